@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"api/search"
 	"encoding/json"
+	"enum"
+	"fmt"
 	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
 	"models"
+	"sort"
+	"time"
 )
 
 type Domain struct {
@@ -20,8 +26,8 @@ type Domain struct {
   - @url http://54.250.244.153:8080/domains/query
   - @param name 选填 string 搜索框的输入
   - @param pageNum 选填 int 页码(默认1)
-  - @param pageSize 选填 int 每页数量(默认100)
-  - @param category 选填  string 分类(10K,)
+  - @param pageSize 选填 int 每页数量(默认100，最大500)
+  - @param category 选填  string 分类(999, 10K, 100K, 3L, palindrome, date，见 /categories)
   - @param typeList 选填  string数组 后缀类型([
     "sats",
     "btc",
@@ -52,9 +58,9 @@ type Domain struct {
 * @method post
 * @url http://54.250.244.153:8080/domains/query
 * @header token 可选 string 设备token
-* @param name 选填 string 搜索框的输入
+* @param name 选填 string 搜索框的输入，可为 xn-- 开头的 punycode
 * @param pageNum 选填 int 页码(默认1)
-* @param pageSize 选填 int 每页数量(默认100)
+* @param pageSize 选填 int 每页数量(默认100，最大500)
 * @param typeList 选填  string数组 后缀类型([
   "sats",
   "btc",
@@ -63,199 +69,250 @@ type Domain struct {
   "unisat",
   "x"
   ])
-* @param orderType 选填  int 排序方式(0:铭文序号倒序 1：铭文需要升序 2：字母升序 3：字母降序 4：铭文余额升序 5: 铭文余额降序 6: 最短字符)
+* @param orderType 选填  int 排序方式(0:铭文序号倒序 1：铭文需要升序 2：字母升序 3：字母降序 4：铭文余额升序 5: 铭文余额降序 6: 最短字符 7: emoji 数量升序)
 * @param startWith 选填  string 开头
 * @param endWith 选填  string 结尾
 * @param minWidth 选填  int 字符最小长度
 * @param maxWidth 选填  int 字符最大长度
-* @param notLike 选填  string 不包含
-* @param wordsType 选填  int 字符类型(1:仅含数字 2:仅含字母 3:仅含Emoji)
+* @param notLike 选填  string 不包含(排除含有该字符串的名称)
+* @param category 选填  string 分类(999, 10K, 100K, 3L, palindrome, date，见 /categories)
+* @param wordsType 选填  int 字符类型(0:仅含数字 1:仅含字母 2:仅含Emoji)
+* @param charset 选填  string 字符集(digit, letter, alnum, emoji, mixed)
+* @param hasDigit 选填  bool 含数字
+* @param hasLetter 选填  bool 含字母
+* @param hasEmoji 选填  bool 含Emoji
+* @param palindrome 选填  bool 回文
+* @param minRun 选填  int 最少连续相同字符数(如 3 表示含 aaa)
+* @param pattern 选填  string 模式(大写字母为变量，相同字母相同字符；N 数字，L 字母；如 AABB、ABBA、NNN-L、AAAA.sats)
+* @param emojiClass 选填  string 纯emoji名称的分类(single:单个emoji sequence:多个emoji zwj:含ZWJ组合序列)
+* @param skinTone 选填  bool 含肤色修饰
+* @param minEmoji 选填  int emoji 最少个数
+* @param maxEmoji 选填  int emoji 最多个数
+* @param owner 选填  string 所有者地址，需为配置网络的有效比特币地址
+* @param minHeight 选填  int 铭刻区块高度下限
+* @param maxHeight 选填  int 铭刻区块高度上限
+* @param q 选填  string 查询语句，如 len:3 suffix:sats digits -contains:0 owner:bc1p... minted:>800000 sort:-value，与其他参数同时传时合并，q 中的条件覆盖同一字段的参数，suffix 追加到 type；也可 GET /domains/query?q=...。语法错误时 data 为 {"field":"q","pos":出错位置,"message":原因}
+* @param scriptType 选填  string 所有者地址类型(p2pkh, p2sh, p2wpkh, p2wsh, p2tr, witness_unknown)
+* @param facets 选填  string数组 需要统计的分面(type, length, charset, category, height, script_type)，返回 facets: {"type":[{"value":"sats","count":1204}]}，模糊查询不支持
+* @param isWord 选填  bool 是词库中的单词
+* @param wordPrefix 选填  bool 以单词开头
+* @param compound 选填  bool 由两个单词组成
+* @param wordlist 选填  string 词库(en, pinyin, names, crypto，见 /dictionaries)
+* @param mode 选填  string 查询方式(不传为包含查询，fuzzy 为按编辑距离的模糊查询，结果按编辑距离、名称升序，候选超过1000个时只取最近的1000个并返回 truncated)
+* @param maxDistance 选填  int 模糊查询的最大编辑距离(默认2，最大3)
+* @param cursor 选填  string 游标分页，传上一页返回的 next_cursor，需与 orderType 一致，此时忽略 pageNum
 * @return {"error_code":0,"data":{"uid":"1","username":"12154545","name":"吴系挂","groupid":2,"reg_time":"1436864169","last_login_time":"0"}}
+* @remark 非模糊查询的结果会缓存，响应带 ETag，请求头 If-None-Match 与之相同且数据未变化时返回 304；redis 不可用时不缓存也不带 ETag
+* @return_param script_type string 所有者地址的脚本类型，如 p2tr
+* @return_param unicode string 名称的 Unicode 形式
+* @return_param ascii string 名称的 punycode 形式，可用于 DNS、URL 等只支持 ASCII 的场景
+* @return_param confusable_with string 同后缀下看起来相同、铭刻更早的名称，有值时需提示用户
 * @return_param groupid int 用户组id
 * @return_param name string 用户昵称
 * @remark 这里是备注信息
 * @number 99
 */
 func (c *Domain) Query() {
-	inputData := make(map[string]interface{}, 0)
-	data := c.Ctx.Input.RequestBody
-	err := json.Unmarshal(data, &inputData)
-	if err != nil {
+	req := search.Request{}
+	if c.Ctx.Input.IsGet() {
+		// GET 只支持查询语句和分页，便于保存链接
+		req.Q = c.GetString("q")
+		req.PageNum, _ = c.GetInt("pageNum")
+		req.PageSize, _ = c.GetInt("pageSize")
+		req.Cursor = c.GetString("cursor")
+	} else if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
 		c.Data["json"] = c.Fail(c.Tr("参数错误"), "解析参数错误")
 		c.ServeJSON()
 		return
 	}
 
-	name, ok := inputData["name"].(string)
-	if !ok {
-		c.Data["json"] = c.Fail(c.Tr("参数错误"), "name参数错误")
+	if verr := req.Validate(); verr != nil {
+		c.Data["json"] = c.Fail(c.Tr(verr.Key), verr.Data())
 		c.ServeJSON()
 		return
 	}
 
-	page, ok := inputData["pageNum"].(float64)
-	if !ok {
-		beego.Info("==pageNum==", page)
-		page = 1
-	}
-
-	pageNum := int(page)
-
-	size, ok := inputData["pageSize"].(float64)
-	if !ok {
-		beego.Info("==pageSize==", size)
-		size = 100
-	}
-	beego.Info("pageSize:", size)
-
-	pageSize := int(size)
-
-	//category, ok := inputData["category"].(string)
-	//if !ok {
-	//	c.Data["json"] = c.Fail(c.Tr("参数错误"), "category参数错误")
-	//	c.ServeJSON()
-	//	return
-	//}
-
-	typeList, ok := inputData["typeList"].([]interface{})
-	if !ok {
-		c.Data["json"] = c.Fail(c.Tr("参数错误"), "typeList参数错误")
-		c.ServeJSON()
+	query := req.Build()
+	qs := query.Apply(c.O.QueryTable(models.DoMainTBName()))
+	if req.Fuzzy() {
+		c.fuzzyQuery(&req, query, qs)
 		return
 	}
 
-	orderType, ok := inputData["orderType"].(int)
+	// 结果由名称数据版本和规范化后的查询决定，同步器写入新数据后版本递增，旧缓存和 ETag 随之失效；
+	// 读不到版本时直接查询，不带 ETag
+	version, ok := domainsVersion()
 	if !ok {
-		orderType = 0
-	}
-
-	startWith, ok := inputData["startWith"].(string)
-	if !ok {
-		c.Data["json"] = c.Fail(c.Tr("参数错误"), "startWith参数错误")
+		result, err := c.runQuery(&req, query, qs, "")
+		if err != nil {
+			beego.Error(err)
+			c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+			c.ServeJSON()
+			return
+		}
+		c.Data["json"] = c.Succ(c.Tr("查询成功"), result)
 		c.ServeJSON()
 		return
 	}
-
-	endWith, ok := inputData["endWith"].(string)
-	if !ok {
-		c.Data["json"] = c.Fail(c.Tr("参数错误"), "endWith参数错误")
-		c.ServeJSON()
+	key := query.CacheKey(req.Facets)
+	if c.notModified(fmt.Sprintf(`"%s-%s"`, version, key)) {
 		return
 	}
-
-	minWidth, ok := inputData["minWidth"].(int)
-	if !ok {
-		minWidth = 0
-	}
-
-	maxWidth, ok := inputData["maxWidth"].(int)
-	if !ok {
-		maxWidth = 0
-	}
-
-	notLike, ok := inputData["notLike"].(string)
-	if !ok {
-		c.Data["json"] = c.Fail(c.Tr("参数错误"), "notLike参数错误")
-		c.ServeJSON()
-		return
+	cacheKey := enum.QueryCachePrefix + version + ":" + key
+	result := QueryResult{}
+	if !readCache(cacheKey, &result) {
+		var err error
+		if result, err = c.runQuery(&req, query, qs, version); err != nil {
+			beego.Error(err)
+			c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+			c.ServeJSON()
+			return
+		}
+		ttl := beego.AppConfig.DefaultInt("query::cache_seconds", 300)
+		writeCache(cacheKey, result, time.Duration(ttl)*time.Second)
 	}
 
-	//wordsType, ok := inputData["wordsType"].(int)
-	//if !ok {
-	//	c.Data["json"] = c.Fail(c.Tr("参数错误"), "wordsType参数错误")
-	//	c.ServeJSON()
-	//	return
-	//}
-
-	//valid := validation.Validation{}
-	//// 2.验证获取到的数据
-	//valid.Numeric(minWidth, "minWidth")
-	//valid.Numeric(maxWidth, "maxWidth")
-	//valid.Max(orderType, 6, "orderType")
-	//
-	//// 3.判断有没有错误
-	//if valid.HasErrors() { // 说明有错误
-	//	for _, err := range valid.Errors { // 循环打印错误
-	//		c.Data["json"] = c.Fail(c.Tr("参数错误"), err.Key)
-	//		c.ServeJSON()
-	//		return
-	//	}
-	//}
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), result)
+	c.ServeJSON()
+}
 
-	session := c.O
+// QueryResult 查询结果
+type QueryResult struct {
+	TotalCount    int64
+	ProfitDetails []models.DoMain
+	NextCursor    string                         `json:"next_cursor"`
+	Facets        map[string][]search.FacetCount `json:"facets,omitempty"`
+}
 
-	var typeLists []string
-	for _, tl := range typeList {
-		typeLists = append(typeLists, tl.(string))
+func (c *Domain) runQuery(req *search.Request, query search.Query, qs orm.QuerySeter, version string) (QueryResult, error) {
+	result := QueryResult{}
+	totalCount, err := qs.Count()
+	if err != nil {
+		return result, err
 	}
 
-	beego.Info("typeLists:", typeLists)
-	qs := session.QueryTable(models.DoMainTBName())
-	if name != "" {
-		qs = qs.Filter("content__icontains", name)
+	domains := make([]models.DoMain, 0)
+	if _, err := query.Page(qs).All(&domains); err != nil {
+		return result, err
 	}
 
-	if typeLists != nil {
-		qs = qs.Filter("type__in", typeLists)
+	list := make([]*models.DoMain, 0, len(domains))
+	for i := range domains {
+		domains[i].SetDisplay()
+		list = append(list, &domains[i])
 	}
-
-	if startWith != "" {
-		qs = qs.Filter("content__istartswith", startWith)
+	if err := markConfusables(c.O, list); err != nil {
+		return result, err
 	}
 
-	if endWith != "" {
-		qs = qs.Filter("content__iendswith", endWith)
+	var nextCursor string
+	if len(domains) == query.Limit {
+		last := domains[len(domains)-1]
+		nextCursor = req.NextCursor(last.Id, sortValue(last, req.SortField()))
 	}
 
-	if minWidth != 0 {
-		qs = qs.Filter("content__len__gte", minWidth)
+	var facets map[string][]search.FacetCount
+	if len(req.Facets) > 0 {
+		if facets, err = c.queryFacets(query, req.Facets, version); err != nil {
+			return result, err
+		}
 	}
 
-	if maxWidth != 0 {
-		qs = qs.Filter("content__len__lte", maxWidth)
-	}
+	return QueryResult{totalCount, domains, nextCursor, facets}, nil
+}
 
-	if notLike != "" {
-		qs = qs.Filter("content__icontains", notLike)
+// sortValue 取排序字段的值，用于生成游标
+func sortValue(domain models.DoMain, field string) interface{} {
+	switch field {
+	case "content":
+		return domain.Content
+	case "value":
+		return domain.Value
+	case "length":
+		return domain.Length
+	case "emoji_count":
+		return domain.EmojiCount
 	}
+	return domain.Id
+}
 
-	totalCount, err := qs.Count()
-	if err != nil {
-		beego.Error(err)
-		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
-		c.ServeJSON()
+// FuzzyDetail 模糊查询的结果，score 为 0-1 的相似度
+type FuzzyDetail struct {
+	models.DoMain
+	Distance int     `json:"distance"`
+	Score    float64 `json:"score"`
+}
+
+// fuzzyQuery 用 BK 树找出候选，其他条件仍由数据库过滤，按编辑距离、名称排序后在内存中分页。
+// 候选超过 MaxFuzzyHits 时只过滤最近的部分，TotalCount 只统计这部分并标记 truncated
+func (c *Domain) fuzzyQuery(req *search.Request, query search.Query, qs orm.QuerySeter) {
+	hits := nameBK.Search(req.Name, req.MaxDistance)
+	truncated := len(hits) > search.MaxFuzzyHits
+	if truncated {
+		hits = hits[:search.MaxFuzzyHits]
+	}
+	details := make([]FuzzyDetail, 0)
+	if len(hits) > 0 {
+		ids := make([]int64, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.Id)
+		}
+		domains := make([]models.DoMain, 0, len(hits))
+		if _, err := qs.Filter("id__in", ids).Limit(len(ids)).All(&domains); err != nil {
+			beego.Error(err)
+			c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+			c.ServeJSON()
+			return
+		}
+		found := make(map[int64]models.DoMain, len(domains))
+		for _, domain := range domains {
+			found[domain.Id] = domain
+		}
+		for _, hit := range hits {
+			if domain, ok := found[hit.Id]; ok {
+				details = append(details, FuzzyDetail{domain, hit.Distance, hit.Score})
+			}
+		}
+		sort.SliceStable(details, func(i, j int) bool {
+			if details[i].Distance != details[j].Distance {
+				return details[i].Distance < details[j].Distance
+			}
+			return details[i].Name < details[j].Name
+		})
 	}
 
-	//orderType 选填  int 排序方式(0:铭文序号倒序 1：铭文需要升序 2：字母升序 3：字母降序 4：铭文余额升序 5: 铭文余额降序 6: 最短字符)
-	if orderType == 1 {
-		qs = qs.OrderBy("id")
-	} else if orderType == 2 {
-		qs = qs.OrderBy("content")
-	} else if orderType == 3 {
-		qs = qs.OrderBy("-content")
-	} else if orderType == 4 {
-		qs = qs.OrderBy("value")
-	} else if orderType == 5 {
-		qs = qs.OrderBy("-value")
-	} else if orderType == 6 {
-		qs = qs.OrderBy("length(content)")
+	totalCount := int64(len(details))
+	if query.Offset < len(details) {
+		details = details[query.Offset:]
 	} else {
-		qs = qs.OrderBy("-id")
+		details = details[:0]
+	}
+	if len(details) > query.Limit {
+		details = details[:query.Limit]
 	}
 
-	domains := make([]models.DoMain, 0)
-	if _, err := qs.Limit(pageSize, (pageNum-1)*pageSize).All(&domains); err != nil {
+	list := make([]*models.DoMain, 0, len(details))
+	for i := range details {
+		details[i].SetDisplay()
+		list = append(list, &details[i].DoMain)
+	}
+	if err := markConfusables(c.O, list); err != nil {
 		beego.Error(err)
 		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
 		c.ServeJSON()
+		return
 	}
 
 	outData := struct {
 		TotalCount    int64
-		ProfitDetails []models.DoMain
+		ProfitDetails []FuzzyDetail
+		NextCursor    string `json:"next_cursor"`
+		Truncated     bool   `json:"truncated"`
 	}{
 		totalCount,
-		domains,
+		details,
+		"",
+		truncated,
 	}
 
 	c.Data["json"] = c.Succ(c.Tr("查询成功"), outData)
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"api/search"
+	"fmt"
+	"models"
+	"utils/bitcoin"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+// btcNetwork 与 syncer 使用同一个网络配置，地址参数按此校验
+var btcNetwork = loadNetwork()
+
+func init() {
+	search.Network = btcNetwork
+}
+
+func loadNetwork() *bitcoin.Params {
+	params, err := bitcoin.Network(beego.AppConfig.String("bitcoin::network"))
+	if err != nil {
+		beego.Error("load bitcoin network err:", err.Error())
+		params, _ = bitcoin.Network(bitcoin.Mainnet)
+	}
+	return params
+}
+
+// bitmap 的命名空间
+const NamespaceBitmap = "bitmap"
+
+type Address struct {
+	BaseController
+}
+
+// Holding 地址持有的一个铭文
+type Holding struct {
+	Kind           string `json:"kind"`
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	InscriptionId  string `json:"inscription_id"`
+	InscriptionNum int64  `json:"inscription_num"`
+	Value          uint64 `json:"value"`
+	Ctime          int64  `json:"ctime"`
+}
+
+// NamespaceTotal 地址在一个命名空间(名称后缀或 bitmap)下持有的数量和铭文余额之和
+type NamespaceTotal struct {
+	Namespace string `json:"namespace"`
+	Count     int64  `json:"count"`
+	Value     uint64 `json:"value"`
+}
+
+// Portfolio 地址持仓，TotalValue 为所有铭文占用的聪数
+type Portfolio struct {
+	Address    string           `json:"address"`
+	ScriptType string           `json:"script_type"`
+	TotalCount int64            `json:"total_count"`
+	TotalValue uint64           `json:"total_value"`
+	Namespaces []NamespaceTotal `json:"namespaces"`
+	Items      interface{}      `json:"items"`
+}
+
+/**
+* showdoc
+* @catalog API接口/地址
+* @title 地址持有的铭文
+* @description 查询地址持有的名称和 bitmap 铭文，按铭文序号倒序，附带每个命名空间的数量和铭文余额之和
+* @method get
+* @url http://54.250.244.153:8080/address/:address/inscriptions
+* @param address 必选 string 比特币地址(base58、bech32 或 bech32m)，需属于配置的网络
+* @param pageNum 选填 int 页码(默认1)
+* @param pageSize 选填 int 每页数量(默认100，最大500)
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"address":"bc1p...","script_type":"p2tr","total_count":3,"total_value":1638,"namespaces":[{"namespace":"sats","count":2,"value":1092},{"namespace":"bitmap","count":1,"value":546}],"items":[{"kind":"name","namespace":"sats","name":"alice.sats","inscription_id":"...i0","inscription_num":123,"value":546,"ctime":1680000000}]}}
+* @return_param script_type string 地址的脚本类型，如 p2tr、p2wpkh、p2pkh
+* @return_param total_count int 持有的铭文总数
+* @return_param total_value int 铭文余额之和(聪)
+* @return_param namespaces array 各命名空间的数量和余额
+* @return_param items array 当前页的铭文，kind 为 name 或 bitmap
+* @number 99
+ */
+func (c *Address) Inscriptions() {
+	address, pageNum, pageSize, ok := c.portfolioParams()
+	if !ok {
+		return
+	}
+
+	portfolio, err := c.portfolio(address, true)
+	if err == nil {
+		items := make([]Holding, 0)
+		sql := fmt.Sprintf(`SELECT * FROM (
+	SELECT 'name' AS kind, type AS namespace, name, inscription_id, id AS inscription_num, value, ctime FROM %s WHERE owner = ?
+	UNION ALL
+	SELECT 'bitmap' AS kind, '%s' AS namespace, CONCAT(block, '.bitmap') AS name, inscription_id, inscription_num, value, ctime FROM %s WHERE owner = ?
+) AS holdings ORDER BY inscription_num DESC LIMIT ? OFFSET ?`, models.DoMainTBName(), NamespaceBitmap, models.BitmapTBName())
+		_, err = c.O.Raw(sql, address, address, pageSize, (pageNum-1)*pageSize).QueryRows(&items)
+		portfolio.Items = items
+	}
+	if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), portfolio)
+	c.ServeJSON()
+}
+
+/**
+* showdoc
+* @catalog API接口/地址
+* @title 地址持有的名称
+* @description 查询地址持有的名称，按铭文序号倒序，附带每个后缀的数量和铭文余额之和
+* @method get
+* @url http://54.250.244.153:8080/address/:address/domains
+* @param address 必选 string 比特币地址(base58、bech32 或 bech32m)，需属于配置的网络
+* @param pageNum 选填 int 页码(默认1)
+* @param pageSize 选填 int 每页数量(默认100，最大500)
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"address":"bc1p...","script_type":"p2tr","total_count":2,"total_value":1092,"namespaces":[{"namespace":"sats","count":2,"value":1092}],"items":[{"id":123,"name":"alice.sats"}]}}
+* @return_param total_count int 持有的名称总数
+* @return_param total_value int 铭文余额之和(聪)
+* @return_param namespaces array 各后缀的数量和余额
+* @return_param items array 当前页的名称，字段同域名查询
+* @number 99
+ */
+func (c *Address) Domains() {
+	address, pageNum, pageSize, ok := c.portfolioParams()
+	if !ok {
+		return
+	}
+
+	portfolio, err := c.portfolio(address, false)
+	if err == nil {
+		domains := make([]models.DoMain, 0)
+		_, err = c.O.QueryTable(models.DoMainTBName()).Filter("owner", address).OrderBy("-id").
+			Limit(pageSize, (pageNum-1)*pageSize).All(&domains)
+		for i := range domains {
+			domains[i].SetDisplay()
+		}
+		portfolio.Items = domains
+	}
+	if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), portfolio)
+	c.ServeJSON()
+}
+
+// portfolioParams 校验地址和分页参数，返回规范形式的地址，失败时已写入响应
+func (c *Address) portfolioParams() (string, int, int, bool) {
+	address, err := bitcoin.Canonical(c.GetString(":address"), btcNetwork)
+	if err != nil {
+		c.Data["json"] = c.Fail(c.Tr("无效的地址"), err.Error())
+		c.ServeJSON()
+		return "", 0, 0, false
+	}
+
+	pageNum, _ := c.GetInt("pageNum", 1)
+	pageSize, _ := c.GetInt("pageSize", search.DefaultPageSize)
+	if pageNum < 1 || pageSize < 1 || pageSize > search.MaxPageSize {
+		c.Data["json"] = c.Fail(c.Tr("无效的分页参数"), nil)
+		c.ServeJSON()
+		return "", 0, 0, false
+	}
+	return address, pageNum, pageSize, true
+}
+
+// portfolio 按命名空间汇总地址的持仓，bitmaps 为 true 时计入 bitmap
+func (c *Address) portfolio(address string, bitmaps bool) (*Portfolio, error) {
+	totals := make([]NamespaceTotal, 0)
+	sql := fmt.Sprintf("SELECT type AS namespace, COUNT(*) AS count, SUM(value) AS value FROM %s WHERE owner = ? GROUP BY type ORDER BY count DESC, type",
+		models.DoMainTBName())
+	if _, err := c.O.Raw(sql, address).QueryRows(&totals); err != nil {
+		return nil, err
+	}
+	if bitmaps {
+		bitmap := NamespaceTotal{Namespace: NamespaceBitmap}
+		sql := fmt.Sprintf("SELECT COUNT(*) AS count, COALESCE(SUM(value), 0) AS value FROM %s WHERE owner = ?", models.BitmapTBName())
+		if err := c.O.Raw(sql, address).QueryRow(&bitmap.Count, &bitmap.Value); err != nil && err != orm.ErrNoRows {
+			return nil, err
+		}
+		if bitmap.Count > 0 {
+			totals = append(totals, bitmap)
+		}
+	}
+
+	portfolio := &Portfolio{Address: address, ScriptType: bitcoin.ScriptType(address, btcNetwork), Namespaces: totals}
+	for _, total := range totals {
+		portfolio.TotalCount += total.Count
+		portfolio.TotalValue += total.Value
+	}
+	return portfolio, nil
+}
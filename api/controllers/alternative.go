@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"api/search"
+)
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 候选名称
+* @description 返回与该名称相近且未注册的名称：其他后缀、前后缀、数字替换、单复数，按得分降序
+* @method get
+* @url http://54.250.244.153:8080/domains/:name/alternatives
+* @param name 必选 string 域名，如 alice.sats
+* @param limit 选填 int 返回数量(默认20，最大100)
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"name":"alice.sats","status":"taken","alternatives":[{"name":"alice.btc","reason":"suffix","score":85}]}}
+* @return_param status string 名称本身的状态(taken, available)
+* @return_param reason string 候选来源(suffix, prefix, affix, digit, plural)
+* @return_param score int 得分
+* @number 99
+ */
+func (c *Domain) Alternatives() {
+	profile := nameProfile()
+	name, ok := profile.NormalizeName(c.GetString(":name"))
+	if !ok {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "name参数错误")
+		c.ServeJSON()
+		return
+	}
+
+	limit, err := c.GetInt("limit", search.DefaultAlternativeSize)
+	if err != nil || limit <= 0 {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "limit")
+		c.ServeJSON()
+		return
+	}
+	if limit > search.MaxAlternativeSize {
+		limit = search.MaxAlternativeSize
+	}
+
+	source := &search.AlternativeSource{
+		Types:     nameTrie.Types(""),
+		Normalize: profile.NormalizeName,
+		Taken: func(label string, typ string) bool {
+			_, ok := nameTrie.Lookup(label, typ)
+			return ok
+		},
+		Dictionary: dictionary,
+	}
+
+	label, typ := splitName(name)
+	status := StatusAvailable
+	if source.Taken(label, typ) {
+		status = StatusTaken
+	}
+
+	outData := struct {
+		Name         string               `json:"name"`
+		Status       string               `json:"status"`
+		Alternatives []search.Alternative `json:"alternatives"`
+	}{
+		name,
+		status,
+		source.Alternatives(label, typ, limit),
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), outData)
+	c.ServeJSON()
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"encoding/json"
+	"models"
+	"strings"
+	"time"
+	"utils/names"
+
+	"github.com/astaxie/beego"
+)
+
+// 名称状态
+const (
+	StatusInvalid   = "invalid"
+	StatusTaken     = "taken"
+	StatusPending   = "pending"
+	StatusAvailable = "available"
+)
+
+const (
+	MaxAvailabilityBatch = 10000
+	// IN 查询每批的名称数
+	availabilityChunk = 1000
+)
+
+type AvailabilityResult struct {
+	Input          string `json:"input"`
+	Name           string `json:"name"`
+	Ascii          string `json:"ascii,omitempty"`
+	Status         string `json:"status"`
+	Owner          string `json:"owner,omitempty"`
+	InscriptionId  string `json:"inscription_id,omitempty"`
+	InscriptionNum int64  `json:"inscription_num,omitempty"`
+}
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 批量可用性查询
+* @description 按 syncer 的规则规范化名称，返回 invalid(无效)、taken(已注册)、pending(有尚未稳定的铭刻，syncer 从 ord 抓取)或 available(可注册)；服务启动后名称索引加载完成前返回 4003
+* @method post
+* @url http://54.250.244.153:8080/domains/availability
+* @param names 必选 string数组 名称列表，如 ["alice.sats","xn--...sats"]，最多10000个
+* @return {"code":1003,"status":true,"message":"query succeed","data":[{"input":"Alice.sats","name":"alice.sats","status":"taken","owner":"bc1p...","inscription_id":"...i0","inscription_num":123}]}
+* @return_param input string 输入
+* @return_param name string 规范化后的名称(Unicode)
+* @return_param ascii string 名称的 punycode 形式
+* @return_param status string 状态
+* @number 99
+ */
+func (c *Domain) Availability() {
+	req := struct {
+		Names []string `json:"names"`
+	}{}
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &req); err != nil {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "解析参数错误")
+		c.ServeJSON()
+		return
+	}
+	if len(req.Names) == 0 || len(req.Names) > MaxAvailabilityBatch {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "names")
+		c.ServeJSON()
+		return
+	}
+
+	// 前缀树在后台加载，首次加载完成前无法判断名称是否已注册
+	if !nameTrie.Loaded() {
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), "名称索引加载中")
+		c.ServeJSON()
+		return
+	}
+
+	profile := nameProfile()
+	results := make([]AvailabilityResult, len(req.Names))
+	// 前缀树里已注册的名称再查库取所有者；前缀树定时刷新，未命中的也查库确认，
+	// 仍未注册的再查未确认的铭刻
+	taken := make(map[string][]int)
+	rest := make(map[string][]int)
+	for i, raw := range req.Names {
+		results[i] = AvailabilityResult{Input: raw, Status: StatusInvalid}
+		name, ok := profile.NormalizeName(raw)
+		if !ok {
+			continue
+		}
+		results[i].Name = name
+		results[i].Ascii = names.ToASCII(name)
+		label, typ := splitName(name)
+		if _, ok := nameTrie.Lookup(label, typ); ok {
+			results[i].Status = StatusTaken
+			taken[name] = append(taken[name], i)
+		} else {
+			results[i].Status = StatusAvailable
+			rest[name] = append(rest[name], i)
+		}
+	}
+
+	if err := c.fillTaken(results, taken); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+	if err := c.fillTaken(results, rest); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+	for name, list := range rest {
+		if results[list[0]].Status == StatusTaken {
+			delete(rest, name)
+		}
+	}
+	if err := c.fillPending(results, rest); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), results)
+	c.ServeJSON()
+}
+
+func (c *Domain) fillTaken(results []AvailabilityResult, taken map[string][]int) error {
+	for _, chunk := range chunkNames(taken) {
+		domains := make([]models.DoMain, 0, len(chunk))
+		if _, err := c.O.QueryTable(models.DoMainTBName()).Filter("name__in", chunk).
+			All(&domains, "id", "name", "owner", "inscription_id"); err != nil {
+			return err
+		}
+		for _, domain := range domains {
+			for _, i := range taken[domain.Name] {
+				results[i].Status = StatusTaken
+				results[i].Owner = domain.Owner
+				results[i].InscriptionId = domain.InscriptionId
+				results[i].InscriptionNum = domain.Id
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Domain) fillPending(results []AvailabilityResult, rest map[string][]int) error {
+	ttl := beego.AppConfig.DefaultInt64("availability::pending_seconds", 24*60*60)
+	since := time.Now().Unix() - ttl
+	for _, chunk := range chunkNames(rest) {
+		claims := make([]models.PendingClaim, 0)
+		if _, err := c.O.QueryTable(models.PendingClaimTBName()).Filter("name__in", chunk).Filter("ctime__gte", since).
+			OrderBy("ctime").All(&claims); err != nil {
+			return err
+		}
+		for _, claim := range claims {
+			for _, i := range rest[claim.Name] {
+				if results[i].Status == StatusPending {
+					continue
+				}
+				results[i].Status = StatusPending
+				results[i].Owner = claim.Owner
+				results[i].InscriptionId = claim.InscriptionId
+			}
+		}
+	}
+	return nil
+}
+
+func chunkNames(set map[string][]int) [][]string {
+	chunks := make([][]string, 0)
+	chunk := make([]string, 0, availabilityChunk)
+	for name := range set {
+		chunk = append(chunk, name)
+		if len(chunk) == availabilityChunk {
+			chunks = append(chunks, chunk)
+			chunk = make([]string, 0, availabilityChunk)
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// splitName 拆分规范化后的名称为标签和后缀类型
+func splitName(name string) (string, string) {
+	i := strings.LastIndex(name, ".")
+	return name[:i], name[i+1:]
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"api/search"
+	"models"
+	"utils/bitcoin"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+type Bitmap struct {
+	BaseController
+}
+
+/**
+* showdoc
+* @catalog API接口/Bitmap
+* @title 按区块查询bitmap
+* @description 按区块高度查询 <block>.bitmap 的铭刻信息
+* @method get
+* @url http://54.250.244.153:8080/bitmaps/:block
+* @param block 必选 int 区块高度
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"block":800000,"inscription_id":"...","owner":"bc1p..."}}
+* @return_param data object bitmap信息
+* @number 99
+ */
+func (c *Bitmap) Block() {
+	block, err := c.GetUint64(":block")
+	if err != nil {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "block参数错误")
+		c.ServeJSON()
+		return
+	}
+
+	bitmap := models.Bitmap{Block: block}
+	if err := c.O.Read(&bitmap, "block"); err == orm.ErrNoRows {
+		c.Ctx.Output.SetStatus(404)
+		c.Data["json"] = c.Fail(c.Tr("未找到"), nil)
+		c.ServeJSON()
+		return
+	} else if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), bitmap)
+	c.ServeJSON()
+}
+
+/**
+* showdoc
+* @catalog API接口/Bitmap
+* @title 按地址查询bitmap
+* @description 查询地址持有的 bitmap
+* @method get
+* @url http://54.250.244.153:8080/bitmaps/owner/:owner
+* @param owner 必选 string 所有者地址
+* @param pageNum 选填 int 页码(默认1)
+* @param pageSize 选填 int 每页数量(默认100，最大500)
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"TotalCount":1,"Bitmaps":[]}}
+* @return_param TotalCount int 总数
+* @return_param Bitmaps array bitmap列表
+* @number 99
+ */
+func (c *Bitmap) Owner() {
+	owner, err := bitcoin.Canonical(c.GetString(":owner"), btcNetwork)
+	if err != nil {
+		c.Data["json"] = c.Fail(c.Tr("无效的地址"), err.Error())
+		c.ServeJSON()
+		return
+	}
+
+	pageNum, _ := c.GetInt("pageNum", 1)
+	pageSize, _ := c.GetInt("pageSize", 100)
+	if pageNum < 1 || pageSize < 1 || pageSize > search.MaxPageSize {
+		c.Data["json"] = c.Fail(c.Tr("无效的分页参数"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	qs := c.O.QueryTable(models.BitmapTBName()).Filter("owner", owner)
+	totalCount, err := qs.Count()
+	if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	bitmaps := make([]models.Bitmap, 0)
+	if _, err := qs.OrderBy("block").Limit(pageSize, (pageNum-1)*pageSize).All(&bitmaps); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	outData := struct {
+		TotalCount int64
+		Bitmaps    []models.Bitmap
+	}{
+		totalCount,
+		bitmaps,
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), outData)
+	c.ServeJSON()
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"encoding/json"
+	"enum"
+	"net/http"
+	"strings"
+	"time"
+	"utils/redis"
+
+	"github.com/astaxie/beego"
+)
+
+// readCache 读取 redis 中以 json 保存的缓存，未命中返回 false
+func readCache(key string, v interface{}) bool {
+	data, err := redis.RedisGet(key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func writeCache(key string, v interface{}, ttl time.Duration) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if _, err := redis.RedisSet(key, data, ttl); err != nil {
+		beego.Error("redis set err:", err)
+	}
+}
+
+// domainsVersion 同步器发布的名称数据版本，尚未发布时为 0。
+// redis 不可用时 ok 为 false，此时无法判断数据是否变化，调用方不能使用缓存和 ETag
+func domainsVersion() (string, bool) {
+	version, err := redis.RedisGet(enum.DomainsVersion).Result()
+	if err == redis.Nil {
+		return "0", true
+	}
+	if err != nil {
+		beego.Error("redis get domains version err:", err)
+		return "", false
+	}
+	return version, true
+}
+
+// notModified 设置 ETag，请求的 If-None-Match 命中时返回 304 并结束本次请求
+func (c *BaseController) notModified(etag string) bool {
+	c.Ctx.Output.Header("ETag", etag)
+	match := c.Ctx.Input.Header("If-None-Match")
+	if match == "" {
+		return false
+	}
+	for _, tag := range strings.Split(match, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			c.Ctx.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"api/search"
+	"fmt"
+	"models"
+	"utils/names"
+
+	"github.com/astaxie/beego"
+)
+
+func init() {
+	search.CategoryTable = models.DomainCategoryTBName()
+}
+
+type Category struct {
+	BaseController
+}
+
+// defaultCategorySuffix 分类成员按后缀计数，不传后缀时统计 sats
+const defaultCategorySuffix = "sats"
+
+type CategoryStat struct {
+	*names.Category
+	Minted int64 `json:"minted"`
+}
+
+type categoryCount struct {
+	Category string
+	Minted   int64
+}
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 分类列表
+* @description 返回全部分类及其成员总数、某个后缀下的已铭刻数量
+* @method get
+* @url http://54.250.244.153:8080/categories
+* @param type 选填 string 后缀类型，默认 sats
+* @return {"code":1003,"status":true,"message":"query succeed","data":[{"name":"999","description":"000-999","size":1000,"minted":812}]}
+* @return_param name string 分类
+* @return_param size int 成员总数
+* @return_param minted int 该后缀下已铭刻数量，不超过 size
+* @number 99
+ */
+func (c *Category) List() {
+	suffix := c.GetString("type", defaultCategorySuffix)
+
+	rows := make([]categoryCount, 0)
+	sql := fmt.Sprintf("SELECT category, COUNT(*) AS minted FROM %s WHERE type = ? GROUP BY category",
+		models.DomainCategoryTBName())
+	if _, err := c.O.Raw(sql, suffix).QueryRows(&rows); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+	minted := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		minted[row.Category] = row.Minted
+	}
+
+	stats := make([]CategoryStat, 0)
+	for _, category := range names.CategoryList() {
+		stats = append(stats, CategoryStat{category, minted[category.Name]})
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), stats)
+	c.ServeJSON()
+}
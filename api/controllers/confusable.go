@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"api/search"
+	"fmt"
+	"models"
+	"utils/names"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 易混淆名称
+* @description 返回同后缀下与该名称看起来相同(骨架相同，按常见易混淆字符表计算，不是完整的 TR39)的已注册名称，按铭文序号升序
+* @method get
+* @url http://54.250.244.153:8080/domains/:name/confusables
+* @param name 必选 string 域名，如 alice.sats
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"name":"alice.sats","skeleton":"alice","confusables":[{"name":"аlice.sats","id":2048}]}}
+* @return_param skeleton string 骨架
+* @return_param confusables array 骨架相同的其他名称
+* @number 99
+ */
+func (c *Domain) Confusables() {
+	name, ok := normalizeName(c.GetString(":name"))
+	if !ok {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "name参数错误")
+		c.ServeJSON()
+		return
+	}
+
+	label, typ := splitName(name)
+	skeleton := names.Skeleton(label)
+	domains := make([]models.DoMain, 0)
+	if _, err := c.O.QueryTable(models.DoMainTBName()).Filter("type", typ).Filter("skeleton", skeleton).
+		Exclude("name", name).OrderBy("id").Limit(-1).All(&domains); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	for i := range domains {
+		domains[i].SetDisplay()
+	}
+
+	outData := struct {
+		Name        string          `json:"name"`
+		Skeleton    string          `json:"skeleton"`
+		Confusables []models.DoMain `json:"confusables"`
+	}{
+		name,
+		skeleton,
+		domains,
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), outData)
+	c.ServeJSON()
+}
+
+type firstSkeleton struct {
+	Type     string
+	Skeleton string
+	Id       int64
+}
+
+// markConfusables 给查询结果填上 confusable_with：同后缀下骨架相同、铭刻更早的名称
+func markConfusables(o orm.Ormer, domains []*models.DoMain) error {
+	skeletons := make([]interface{}, 0, len(domains))
+	types := make([]interface{}, 0)
+	seenSkeleton := make(map[string]bool)
+	seenType := make(map[string]bool)
+	for _, domain := range domains {
+		if domain.Skeleton == "" {
+			continue
+		}
+		if !seenSkeleton[domain.Skeleton] {
+			seenSkeleton[domain.Skeleton] = true
+			skeletons = append(skeletons, domain.Skeleton)
+		}
+		if !seenType[domain.Type] {
+			seenType[domain.Type] = true
+			types = append(types, domain.Type)
+		}
+	}
+	if len(skeletons) == 0 {
+		return nil
+	}
+
+	// 每个后缀和骨架只取铭刻最早的一个
+	rows := make([]firstSkeleton, 0)
+	sql := fmt.Sprintf("SELECT type, skeleton, MIN(id) AS id FROM %s WHERE type IN (%s) AND skeleton IN (%s) GROUP BY type, skeleton",
+		models.DoMainTBName(), search.Placeholders(len(types)), search.Placeholders(len(skeletons)))
+	if _, err := o.Raw(sql, append(types, skeletons...)...).QueryRows(&rows); err != nil {
+		return err
+	}
+	first := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		first[row.Type+"."+row.Skeleton] = row.Id
+	}
+
+	// 只读取比结果中名称更早的那些名称
+	ids := make([]int64, 0)
+	for _, domain := range domains {
+		if id, ok := first[domain.Type+"."+domain.Skeleton]; ok && id < domain.Id {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	others := make([]models.DoMain, 0, len(ids))
+	if _, err := o.QueryTable(models.DoMainTBName()).Filter("id__in", ids).All(&others, "id", "name"); err != nil {
+		return err
+	}
+	byId := make(map[int64]string, len(others))
+	for _, other := range others {
+		byId[other.Id] = other.Name
+	}
+	for _, domain := range domains {
+		if id, ok := first[domain.Type+"."+domain.Skeleton]; ok && id < domain.Id {
+			domain.ConfusableWith = byId[id]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"api/search"
+	"models"
+	"strings"
+	"utils/names"
+
+	"github.com/astaxie/beego"
+)
+
+// dictionary 与 syncer 使用同一份词库配置
+var dictionary = loadDictionary()
+
+func init() {
+	search.WordTable = models.DomainWordTBName()
+	search.Dictionary = dictionary
+}
+
+func loadDictionary() *names.Dictionary {
+	dir := beego.AppConfig.DefaultString("dictionary::dir", "../conf/words")
+	lists := strings.Split(beego.AppConfig.String("dictionary::lists"), "|")
+	d, err := names.LoadDictionary(dir, lists)
+	if err != nil {
+		beego.Error("load dictionary err:", err.Error())
+		d, _ = names.LoadDictionary(dir, nil)
+	}
+	return d
+}
+
+type Dictionary struct {
+	BaseController
+}
+
+type WordlistStat struct {
+	Name   string `json:"name"`
+	Words  int    `json:"words"`
+	Minted int64  `json:"minted"`
+}
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 词库列表
+* @description 返回已加载的词库及其单词数、已铭刻的单词名称数量
+* @method get
+* @url http://54.250.244.153:8080/dictionaries
+* @param type 选填 string 后缀类型，如 sats，不传统计全部后缀
+* @return {"code":1003,"status":true,"message":"query succeed","data":[{"name":"en","words":347,"minted":120}]}
+* @return_param name string 词库
+* @return_param words int 单词数
+* @return_param minted int 已铭刻数量
+* @number 99
+ */
+func (c *Dictionary) List() {
+	suffix := c.GetString("type")
+
+	stats := make([]WordlistStat, 0)
+	for _, list := range dictionary.Lists() {
+		qs := c.O.QueryTable(models.DomainWordTBName()).Filter("list", list)
+		if suffix != "" {
+			qs = qs.Filter("type", suffix)
+		}
+		minted, err := qs.Count()
+		if err != nil {
+			beego.Error(err)
+			c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+			c.ServeJSON()
+			return
+		}
+		stats = append(stats, WordlistStat{list, dictionary.Count(list), minted})
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), stats)
+	c.ServeJSON()
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"api/search"
+	"enum"
+	"fmt"
+	"models"
+	"sort"
+	"time"
+	"utils/bitcoin"
+	"utils/names"
+
+	"github.com/astaxie/beego"
+)
+
+// queryFacets 统计当前条件下各分面的数量，结果按名称数据版本和规范化后的条件缓存在 redis，
+// version 为空(读不到数据版本)时不读写缓存
+func (c *Domain) queryFacets(query search.Query, facets []string, version string) (map[string][]search.FacetCount, error) {
+	key := enum.FacetCachePrefix + version + ":" + query.FacetKey(facets)
+	out := make(map[string][]search.FacetCount, len(facets))
+	if version != "" && readCache(key, &out) {
+		return out, nil
+	}
+
+	where, args, err := query.Where()
+	if err != nil {
+		return nil, err
+	}
+	for _, facet := range facets {
+		var counts []search.FacetCount
+		var err error
+		switch facet {
+		case search.FacetType:
+			counts, err = c.countValues(where, args, "type", nil)
+			sort.SliceStable(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+		case search.FacetCharset:
+			counts, err = c.countValues(where, args, "charset", []string{names.CharsetDigit, names.CharsetLetter,
+				names.CharsetAlnum, names.CharsetEmoji, names.CharsetMixed})
+		case search.FacetLength:
+			counts, err = c.countBuckets(where, args, "length", search.LengthBuckets)
+		case search.FacetCategory:
+			counts, err = c.countCategories(where, args)
+		case search.FacetHeight:
+			counts, err = c.countHeights(where, args)
+		case search.FacetScriptType:
+			counts, err = c.countValues(where, args, "script_type", append([]string{}, bitcoin.ScriptTypes...))
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[facet] = counts
+	}
+
+	if version != "" {
+		ttl := beego.AppConfig.DefaultInt("facet::cache_seconds", 60)
+		writeCache(key, out, time.Duration(ttl)*time.Second)
+	}
+	return out, nil
+}
+
+// countValues 用一条 GROUP BY 按字段的取值计数，返回数量大于 0 的取值，按取值排序；
+// values 不为 nil 时只返回其中的取值
+func (c *Domain) countValues(where string, args []interface{}, field string, values []string) ([]search.FacetCount, error) {
+	rows := make([]search.FacetCount, 0)
+	sql := fmt.Sprintf("SELECT `%s` AS value, COUNT(*) AS count FROM %s WHERE %s GROUP BY `%s`",
+		field, models.DoMainTBName(), where, field)
+	if _, err := c.O.Raw(sql, args...).QueryRows(&rows); err != nil {
+		return nil, err
+	}
+	found := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		found[row.Value] = row.Count
+	}
+
+	if values == nil {
+		for _, row := range rows {
+			values = append(values, row.Value)
+		}
+	}
+	sort.Strings(values)
+	counts := make([]search.FacetCount, 0, len(values))
+	for _, v := range values {
+		if n := found[v]; n > 0 {
+			counts = append(counts, search.FacetCount{Value: v, Count: n})
+		}
+	}
+	return counts, nil
+}
+
+// countBuckets 用一条 GROUP BY 按字段所在的区间计数，按区间的顺序返回
+func (c *Domain) countBuckets(where string, args []interface{}, field string, buckets []search.Bucket) ([]search.FacetCount, error) {
+	rows := make([]search.FacetCount, 0)
+	sql := fmt.Sprintf("SELECT %s AS value, COUNT(*) AS count FROM %s WHERE %s GROUP BY value",
+		search.BucketCase(field, buckets), models.DoMainTBName(), where)
+	if _, err := c.O.Raw(sql, args...).QueryRows(&rows); err != nil {
+		return nil, err
+	}
+	found := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		found[row.Value] = row.Count
+	}
+
+	counts := make([]search.FacetCount, 0, len(buckets))
+	for _, b := range buckets {
+		if n := found[b.Label]; n > 0 {
+			counts = append(counts, search.FacetCount{Value: b.Label, Count: n})
+		}
+	}
+	return counts, nil
+}
+
+// countCategories 用一条 GROUP BY 统计命中名称所属的分类，按分类定义的顺序返回
+func (c *Domain) countCategories(where string, args []interface{}) ([]search.FacetCount, error) {
+	rows := make([]search.FacetCount, 0)
+	sql := fmt.Sprintf("SELECT category AS value, COUNT(*) AS count FROM %s WHERE domain_id IN (SELECT id FROM %s WHERE %s) GROUP BY category",
+		models.DomainCategoryTBName(), models.DoMainTBName(), where)
+	if _, err := c.O.Raw(sql, args...).QueryRows(&rows); err != nil {
+		return nil, err
+	}
+	found := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		found[row.Value] = row.Count
+	}
+
+	counts := make([]search.FacetCount, 0)
+	for _, category := range names.CategoryList() {
+		if n := found[category.Name]; n > 0 {
+			counts = append(counts, search.FacetCount{Value: category.Name, Count: n})
+		}
+	}
+	return counts, nil
+}
+
+type heightCount struct {
+	Bucket uint64
+	Count  int64
+}
+
+// countHeights 在当前条件的最低和最高铭刻高度之间分段计数，高度未知(0)的名称不计入；
+// 一条查询取高度范围，一条 GROUP BY 按段计数
+func (c *Domain) countHeights(where string, args []interface{}) ([]search.FacetCount, error) {
+	where = "(" + where + ") AND height > 0"
+	var n int64
+	var min, max uint64
+	sql := fmt.Sprintf("SELECT COUNT(*), COALESCE(MIN(height), 0), COALESCE(MAX(height), 0) FROM %s WHERE %s",
+		models.DoMainTBName(), where)
+	if err := c.O.Raw(sql, args...).QueryRow(&n, &min, &max); err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return make([]search.FacetCount, 0), nil
+	}
+
+	step := search.HeightStep(min, max, beego.AppConfig.DefaultInt64("facet::height_step", search.DefaultHeightStep))
+	rows := make([]heightCount, 0)
+	sql = fmt.Sprintf("SELECT FLOOR(height / %d) AS bucket, COUNT(*) AS count FROM %s WHERE %s GROUP BY bucket",
+		step, models.DoMainTBName(), where)
+	if _, err := c.O.Raw(sql, args...).QueryRows(&rows); err != nil {
+		return nil, err
+	}
+	found := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		found[row.Bucket*step] = row.Count
+	}
+
+	counts := make([]search.FacetCount, 0)
+	for _, b := range search.HeightBuckets(min, max, step) {
+		if n := found[b.Min]; n > 0 {
+			counts = append(counts, search.FacetCount{Value: b.Label, Count: n})
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"models"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+	"utils/blob"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+// 铭文内容不可变，客户端和 CDN 可以长期缓存
+const contentCacheControl = "public, max-age=31536000, immutable"
+
+// 与 ord 相同的内容安全策略，铭文中的 html 和 svg 不能访问其他来源
+const contentSecurityPolicy = "default-src 'self' 'unsafe-eval' 'unsafe-inline' data: blob:"
+
+var inscriptionIdPattern = regexp.MustCompile(`^[0-9a-f]{64}i[0-9]+$`)
+
+var ordClient = &http.Client{Timeout: 30 * time.Second}
+
+type Inscription struct {
+	BaseController
+}
+
+// InscriptionDetail 铭文元数据和协议解析结果
+type InscriptionDetail struct {
+	models.Inscription
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	ContentURL string          `json:"content_url"`
+}
+
+/**
+* showdoc
+* @catalog API接口/铭文
+* @title 铭文详情
+* @description 按铭文id或序号查询 syncer 保存的铭文元数据，附带协议解析结果
+* @method get
+* @url http://54.250.244.153:8080/inscriptions/:id
+* @param id 必选 string 铭文id(如 6fb9...i0)或铭文序号
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"id":123,"inscription_id":"...i0","content_type":"text/plain;charset=utf-8","content_length":10,"owner":"bc1p...","value":546,"genesis_height":790000,"genesis_fee":1200,"ctime":1680000000,"protocol":"sns","payload":{"name":"alice.sats"},"content_url":"/inscriptions/...i0/content"}}
+* @return_param protocol string 协议：sns(名称)、sns-update、sns-primary、bitmap、brc-721-deploy、brc-721-mint、brc-721-update，非协议铭文为空
+* @return_param payload object 协议解析结果
+* @return_param content_url string 内容地址
+* @number 99
+ */
+func (c *Inscription) Detail() {
+	inscription, ok := c.findInscription(c.GetString(":id"))
+	if !ok {
+		return
+	}
+
+	detail := InscriptionDetail{
+		Inscription: *inscription,
+		ContentURL:  "/inscriptions/" + inscription.InscriptionId + "/content",
+	}
+	if inscription.Payload != "" {
+		detail.Payload = json.RawMessage(inscription.Payload)
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), detail)
+	c.ServeJSON()
+}
+
+/**
+* showdoc
+* @catalog API接口/铭文
+* @title 铭文内容
+* @description 返回铭文内容，Content-Type 与铭刻时一致，带长期缓存的 Cache-Control 和 ETag；内容优先从本地内容存储读取，没有时从 ord 读取
+* @method get
+* @url http://54.250.244.153:8080/inscriptions/:id/content
+* @param id 必选 string 铭文id或铭文序号
+* @return 铭文内容
+* @number 99
+ */
+func (c *Inscription) Content() {
+	id := c.GetString(":id")
+	var inscription *models.Inscription
+	if inscriptionIdPattern.MatchString(id) {
+		// 未索引的铭文仍可从 ord 读取
+		row := models.Inscription{InscriptionId: id}
+		if err := c.O.Read(&row, "inscription_id"); err == nil {
+			inscription = &row
+		} else if err != orm.ErrNoRows {
+			beego.Error(err)
+			c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+			c.ServeJSON()
+			return
+		}
+	} else {
+		var ok bool
+		if inscription, ok = c.findInscription(id); !ok {
+			return
+		}
+		id = inscription.InscriptionId
+	}
+
+	// 304 之前先确认铭文存在：已索引的铭文由数据库确认，未索引的要先从 ord 读到内容
+	var body io.ReadCloser
+	var contentType string
+	if inscription == nil {
+		var ok bool
+		if body, contentType, ok = c.openContent(id, nil); !ok {
+			return
+		}
+		defer body.Close()
+	}
+
+	header := c.Ctx.ResponseWriter.Header()
+	header.Set("Cache-Control", contentCacheControl)
+	if c.notModified(`"` + id + `"`) {
+		return
+	}
+
+	if body == nil {
+		var ok bool
+		if body, contentType, ok = c.openContent(id, inscription); !ok {
+			return
+		}
+		defer body.Close()
+	}
+
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	header.Set("Content-Security-Policy", contentSecurityPolicy)
+	header.Set("X-Content-Type-Options", "nosniff")
+	c.Ctx.ResponseWriter.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(c.Ctx.ResponseWriter, body); err != nil {
+		beego.Error("copy inscription content err:", err)
+	}
+}
+
+// openContent 读取铭文内容，失败时已写入响应
+func (c *Inscription) openContent(id string, inscription *models.Inscription) (io.ReadCloser, string, bool) {
+	body, contentType, err := openContent(id, inscription)
+	if err != nil {
+		// 错误响应不能带内容的长期缓存头
+		header := c.Ctx.ResponseWriter.Header()
+		header.Del("Cache-Control")
+		header.Del("ETag")
+	}
+	if err == errContentNotFound {
+		c.Ctx.Output.SetStatus(http.StatusNotFound)
+		c.Data["json"] = c.Fail(c.Tr("未找到"), nil)
+		c.ServeJSON()
+		return nil, "", false
+	} else if err != nil {
+		beego.Error(err)
+		c.Ctx.Output.SetStatus(http.StatusBadGateway)
+		c.Data["json"] = c.Fail(c.Tr("网络异常请重试"), nil)
+		c.ServeJSON()
+		return nil, "", false
+	}
+	return body, contentType, true
+}
+
+// findInscription 按铭文id或序号读取铭文，失败时已写入响应
+func (c *Inscription) findInscription(key string) (*models.Inscription, bool) {
+	inscription := models.Inscription{}
+	var err error
+	if number, perr := strconv.ParseInt(key, 10, 64); perr == nil {
+		inscription.Id = number
+		err = c.O.Read(&inscription)
+	} else if inscriptionIdPattern.MatchString(key) {
+		inscription.InscriptionId = key
+		err = c.O.Read(&inscription, "inscription_id")
+	} else {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "id参数错误")
+		c.ServeJSON()
+		return nil, false
+	}
+
+	if err == orm.ErrNoRows {
+		c.Ctx.Output.SetStatus(http.StatusNotFound)
+		c.Data["json"] = c.Fail(c.Tr("未找到"), nil)
+		c.ServeJSON()
+		return nil, false
+	} else if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return nil, false
+	}
+	return &inscription, true
+}
+
+var errContentNotFound = errors.New("inscription content not found")
+
+// contentStore 与 syncer 共用的内容存储，未配置时为 nil
+var contentStore = loadContentStore()
+
+func loadContentStore() blob.Store {
+	dir := beego.AppConfig.String("content::dir")
+	if dir == "" {
+		return nil
+	}
+	store, err := blob.NewFSStore(dir)
+	if err != nil {
+		beego.Error("open content store err:", err.Error())
+		return nil
+	}
+	return store
+}
+
+// openContent 读取铭文内容，返回内容和 Content-Type。
+// 已索引且内容在本地存储中时直接读取，否则从 ord 读取
+func openContent(id string, inscription *models.Inscription) (io.ReadCloser, string, error) {
+	if contentStore != nil && inscription != nil && inscription.ContentHash != "" {
+		body, err := contentStore.Open(inscription.ContentHash)
+		if err == nil {
+			return body, inscription.ContentType, nil
+		}
+		if err != blob.ErrNotFound {
+			beego.Error("open content err:", err.Error())
+		}
+	}
+
+	contentURL, err := url.JoinPath(beego.AppConfig.String("ord::addr"), "content", id)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := ordClient.Get(contentURL)
+	if err != nil {
+		return nil, "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		contentType := resp.Header.Get("Content-Type")
+		if contentType == "" && inscription != nil {
+			contentType = inscription.ContentType
+		}
+		return resp.Body, contentType, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, "", errContentNotFound
+	}
+	resp.Body.Close()
+	return nil, "", fmt.Errorf("ord content %s: %s", id, resp.Status)
+}
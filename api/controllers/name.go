@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"utils/names"
+
+	"github.com/astaxie/beego"
+)
+
+// nameProfile returns the rules the syncer indexes names with, so the api
+// normalizes user input the same way.
+func nameProfile() *names.Profile {
+	if name := beego.AppConfig.String("ord::profile"); name != "" {
+		if profile, err := names.GetProfile(name); err == nil {
+			return profile
+		}
+	}
+	return names.DefaultProfile()
+}
+
+// normalizeName 按 syncer 的规则规范化名称，xn-- 开头的 punycode 标签解码为 Unicode
+func normalizeName(raw string) (string, bool) {
+	return nameProfile().NormalizeName(raw)
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"models"
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 域名记录
+* @description 查询域名绑定的记录(btc_address, ln_address, nostr, avatar, url)
+* @method get
+* @url http://54.250.244.153:8080/domains/:name/records
+* @param name 必选 string 域名，如 alice.sats
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"Name":"alice.sats","Records":{"btc_address":"bc1p..."}}}
+* @return_param Name string 域名
+* @return_param Records object 记录
+* @number 99
+ */
+func (c *Domain) Records() {
+	name := strings.ToLower(c.GetString(":name"))
+	if name == "" {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "name参数错误")
+		c.ServeJSON()
+		return
+	}
+
+	domain := models.DoMain{Name: name}
+	if err := c.O.Read(&domain, "name"); err == orm.ErrNoRows {
+		c.Ctx.Output.SetStatus(404)
+		c.Data["json"] = c.Fail(c.Tr("未找到"), nil)
+		c.ServeJSON()
+		return
+	} else if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	records, err := domainRecords(c.O, domain.Name)
+	if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	outData := struct {
+		Name    string
+		Records map[string]string
+	}{
+		domain.Name,
+		records,
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), outData)
+	c.ServeJSON()
+}
+
+func domainRecords(o orm.Ormer, name string) (map[string]string, error) {
+	list := make([]models.DomainRecord, 0)
+	if _, err := o.QueryTable(models.DomainRecordTBName()).Filter("name", name).All(&list); err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]string, len(list))
+	for _, record := range list {
+		records[record.Key] = record.Value
+	}
+	return records, nil
+}
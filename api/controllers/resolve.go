@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"enum"
+	"models"
+	"time"
+	"utils/bitcoin"
+	"utils/names"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	resolveCacheTime = 60 * time.Second
+)
+
+type Resolve struct {
+	BaseController
+}
+
+type ResolveResult struct {
+	Name           string            `json:"name"`
+	Unicode        string            `json:"unicode"`
+	Ascii          string            `json:"ascii"`
+	Owner          string            `json:"owner"`
+	InscriptionId  string            `json:"inscription_id"`
+	InscriptionNum int64             `json:"inscription_num"`
+	Records        map[string]string `json:"records"`
+}
+
+type ReverseResult struct {
+	Address string   `json:"address"`
+	Primary string   `json:"primary"`
+	Names   []string `json:"names"`
+}
+
+/**
+* showdoc
+* @catalog API接口/域名解析
+* @title 正向解析
+* @description 根据域名返回所有者地址、铭文和记录
+* @method get
+* @url http://54.250.244.153:8080/resolve/:name
+* @param name 必选 string 域名，如 alice.sats，可为 xn-- 开头的 punycode
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"name":"alice.sats","unicode":"alice.sats","ascii":"alice.sats","owner":"bc1p...","inscription_id":"...i0","inscription_num":123,"records":{}}}
+* @return_param owner string 所有者地址
+* @return_param records object 记录
+* @number 99
+ */
+func (c *Resolve) Resolve() {
+	name, ok := normalizeName(c.GetString(":name"))
+	if !ok {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "name参数错误")
+		c.ServeJSON()
+		return
+	}
+
+	out := ResolveResult{}
+	if readCache(enum.ResolveCachePrefix+name, &out) {
+		c.Data["json"] = c.Succ(c.Tr("查询成功"), out)
+		c.ServeJSON()
+		return
+	}
+
+	domain := models.DoMain{Name: name}
+	if err := c.O.Read(&domain, "name"); err == orm.ErrNoRows {
+		c.Ctx.Output.SetStatus(404)
+		c.Data["json"] = c.Fail(c.Tr("未找到"), nil)
+		c.ServeJSON()
+		return
+	} else if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	records, err := domainRecords(c.O, domain.Name)
+	if err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	out = ResolveResult{
+		Name:           domain.Name,
+		Unicode:        domain.Name,
+		Ascii:          names.ToASCII(domain.Name),
+		Owner:          domain.Owner,
+		InscriptionId:  domain.InscriptionId,
+		InscriptionNum: domain.Id,
+		Records:        records,
+	}
+	writeCache(enum.ResolveCachePrefix+name, out, resolveCacheTime)
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), out)
+	c.ServeJSON()
+}
+
+/**
+* showdoc
+* @catalog API接口/域名解析
+* @title 反向解析
+* @description 根据地址返回主域名和持有的全部域名
+* @method get
+* @url http://54.250.244.153:8080/reverse/:address
+* @param address 必选 string 地址
+* @return {"code":1003,"status":true,"message":"query succeed","data":{"address":"bc1p...","primary":"alice.sats","names":["alice.sats"]}}
+* @return_param primary string 主域名
+* @return_param names array 持有的域名
+* @number 99
+ */
+func (c *Resolve) Reverse() {
+	address, err := bitcoin.Canonical(c.GetString(":address"), btcNetwork)
+	if err != nil {
+		c.Data["json"] = c.Fail(c.Tr("无效的地址"), err.Error())
+		c.ServeJSON()
+		return
+	}
+
+	out := ReverseResult{}
+	if readCache(enum.ReverseCachePrefix+address, &out) {
+		c.Data["json"] = c.Succ(c.Tr("查询成功"), out)
+		c.ServeJSON()
+		return
+	}
+
+	domains := make([]models.DoMain, 0)
+	if _, err := c.O.QueryTable(models.DoMainTBName()).Filter("owner", address).OrderBy("id").All(&domains, "Name"); err != nil {
+		beego.Error(err)
+		c.Data["json"] = c.Fail(c.Tr("服务异常请重试"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	if len(domains) == 0 {
+		c.Ctx.Output.SetStatus(404)
+		c.Data["json"] = c.Fail(c.Tr("未找到"), nil)
+		c.ServeJSON()
+		return
+	}
+
+	out = ReverseResult{Address: address, Names: make([]string, 0, len(domains))}
+	for _, domain := range domains {
+		out.Names = append(out.Names, domain.Name)
+	}
+	out.Primary = c.primaryName(address, out.Names)
+	writeCache(enum.ReverseCachePrefix+address, out, resolveCacheTime)
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), out)
+	c.ServeJSON()
+}
+
+// primaryName returns the name the address selected, provided it still holds
+// it, otherwise the earliest registered one.
+func (c *Resolve) primaryName(address string, names []string) string {
+	primary := models.PrimaryName{Address: address}
+	if err := c.O.Read(&primary, "address"); err == nil {
+		for _, name := range names {
+			if name == primary.Name {
+				return name
+			}
+		}
+	} else if err != orm.ErrNoRows {
+		beego.Error(err)
+	}
+	return names[0]
+}
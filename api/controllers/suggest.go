@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"api/search"
+	"models"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+)
+
+// nameTrie 全部已注册名称的前缀树，启动时加载，之后定时加载新铭刻的名称；
+// 输入补全和可用性查询共用
+var nameTrie = search.NewTrie()
+
+// nameBK 全部已注册名称标签的 BK 树，用于模糊查询，与 nameTrie 一起加载
+var nameBK = search.NewBKTree()
+
+func init() {
+	go loadNameTrie()
+}
+
+// loadNameTrie 按铭文序号增量加载，syncer 按序号顺序写入，只需加载比已有最大序号更大的
+func loadNameTrie() {
+	interval := beego.AppConfig.DefaultInt("suggest::refresh_seconds", 5)
+	o := orm.NewOrm()
+	for {
+		if err := refreshNameTrie(o); err != nil {
+			beego.Error("load name trie err:", err.Error())
+		} else {
+			nameTrie.SetLoaded()
+		}
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
+func refreshNameTrie(o orm.Ormer) error {
+	const batchSize = 5000
+	for {
+		domains := make([]models.DoMain, 0, batchSize)
+		_, err := o.QueryTable(models.DoMainTBName()).Filter("id__gt", nameTrie.LastId()).OrderBy("id").Limit(batchSize).
+			All(&domains, "id", "name", "content", "type", "length")
+		if err != nil {
+			return err
+		}
+		for _, domain := range domains {
+			label := strings.ToLower(domain.Content)
+			nameBK.Insert(label, domain.Id)
+			nameTrie.Insert(label, search.Suggestion{
+				Name:   domain.Name,
+				Type:   domain.Type,
+				Id:     domain.Id,
+				Length: domain.Length,
+			})
+		}
+		if len(domains) < batchSize {
+			return nil
+		}
+	}
+}
+
+/**
+* showdoc
+* @catalog API接口/域名查询
+* @title 输入补全
+* @description 返回以输入开头的已注册名称，按长度、铭文序号升序
+* @method get
+* @url http://54.250.244.153:8080/domains/suggest
+* @param q 必选 string 输入，可带后缀前缀，如 abc 或 abc.sa
+* @param type 选填 string 后缀类型，如 sats
+* @param limit 选填 int 返回数量(默认10，最大50)
+* @return {"code":1003,"status":true,"message":"query succeed","data":[{"name":"abc.sats","type":"sats","id":1024,"length":3}]}
+* @return_param name string 域名
+* @return_param type string 后缀类型
+* @return_param id int 铭文序号
+* @return_param length int 字符长度
+* @number 99
+ */
+func (c *Domain) Suggest() {
+	prefix, typePrefix, hasType := search.SplitSuggest(c.GetString("q"))
+	if prefix == "" {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "q")
+		c.ServeJSON()
+		return
+	}
+
+	limit, err := c.GetInt("limit", search.DefaultSuggestSize)
+	if err != nil || limit <= 0 {
+		c.Data["json"] = c.Fail(c.Tr("参数错误"), "limit")
+		c.ServeJSON()
+		return
+	}
+	if limit > search.MaxSuggestSize {
+		limit = search.MaxSuggestSize
+	}
+
+	var types []string
+	if suffix := c.GetString("type"); suffix != "" {
+		types = []string{suffix}
+	} else if hasType {
+		types = nameTrie.Types(typePrefix)
+	}
+
+	list := make([]search.Suggestion, 0)
+	if !hasType || len(types) > 0 {
+		list = nameTrie.Suggest(prefix, types, limit)
+	}
+
+	c.Data["json"] = c.Succ(c.Tr("查询成功"), list)
+	c.ServeJSON()
+}
@@ -6,5 +6,20 @@ import (
 )
 
 func init() {
-	beego.Router("/domains/query", &controllers.Domain{}, "post:Query")
+	beego.Router("/domains/query", &controllers.Domain{}, "post:Query;get:Query")
+	beego.Router("/domains/availability", &controllers.Domain{}, "post:Availability")
+	beego.Router("/domains/suggest", &controllers.Domain{}, "get:Suggest")
+	beego.Router("/domains/:name/records", &controllers.Domain{}, "get:Records")
+	beego.Router("/domains/:name/confusables", &controllers.Domain{}, "get:Confusables")
+	beego.Router("/domains/:name/alternatives", &controllers.Domain{}, "get:Alternatives")
+	beego.Router("/categories", &controllers.Category{}, "get:List")
+	beego.Router("/dictionaries", &controllers.Dictionary{}, "get:List")
+	beego.Router("/resolve/:name", &controllers.Resolve{}, "get:Resolve")
+	beego.Router("/reverse/:address", &controllers.Resolve{}, "get:Reverse")
+	beego.Router("/bitmaps/:block:int", &controllers.Bitmap{}, "get:Block")
+	beego.Router("/bitmaps/owner/:owner", &controllers.Bitmap{}, "get:Owner")
+	beego.Router("/address/:address/inscriptions", &controllers.Address{}, "get:Inscriptions")
+	beego.Router("/address/:address/domains", &controllers.Address{}, "get:Domains")
+	beego.Router("/inscriptions/:id", &controllers.Inscription{}, "get:Detail")
+	beego.Router("/inscriptions/:id/content", &controllers.Inscription{}, "get:Content")
 }
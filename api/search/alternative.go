@@ -0,0 +1,142 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"utils/names"
+)
+
+const (
+	DefaultAlternativeSize = 20
+	MaxAlternativeSize     = 100
+)
+
+// 变体的来源
+const (
+	ReasonSuffix = "suffix"
+	ReasonPrefix = "prefix"
+	ReasonAffix  = "affix"
+	ReasonDigit  = "digit"
+	ReasonPlural = "plural"
+)
+
+// 常见的前后缀变体
+var (
+	alternativePrefixes = []string{"the", "my", "get", "go", "x"}
+	alternativeSuffixes = []string{"hq", "app", "dao", "x", "0", "1"}
+)
+
+// Alternative 一个可注册的候选名称
+type Alternative struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Score  int    `json:"score"`
+}
+
+// AlternativeSource 生成候选时用到的命名规则和可用性索引
+type AlternativeSource struct {
+	// 已知的后缀类型
+	Types []string
+	// 名称是否符合命名规则，返回规范化后的名称
+	Normalize func(name string) (string, bool)
+	// 名称是否已注册
+	Taken func(label string, typ string) bool
+	// 可为 nil
+	Dictionary *names.Dictionary
+}
+
+// Alternatives 为 label.typ 生成未注册的候选名称，按得分降序
+func (s *AlternativeSource) Alternatives(label string, typ string, n int) []Alternative {
+	seen := map[string]bool{label + "." + typ: true}
+	list := make([]Alternative, 0)
+	add := func(label string, typ string, reason string) {
+		name, ok := s.Normalize(label + "." + typ)
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		i := strings.LastIndex(name, ".")
+		if s.Taken(name[:i], name[i+1:]) {
+			return
+		}
+		list = append(list, Alternative{Name: name, Reason: reason, Score: s.score(name[:i], reason)})
+	}
+
+	for _, other := range s.Types {
+		add(label, other, ReasonSuffix)
+	}
+	for _, prefix := range alternativePrefixes {
+		add(prefix+label, typ, ReasonPrefix)
+	}
+	for _, suffix := range alternativeSuffixes {
+		add(label+suffix, typ, ReasonAffix)
+	}
+	for _, variant := range digitSwaps(label) {
+		add(variant, typ, ReasonDigit)
+	}
+	for _, variant := range plurals(label) {
+		add(variant, typ, ReasonPlural)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Name < list[j].Name
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// score 简单的好坏评分：越短越好，词典单词和俱乐部分类加分，同一标签换后缀最接近原名
+func (s *AlternativeSource) score(label string, reason string) int {
+	traits := names.ComputeTraits(label)
+	score := 100 - 5*traits.Length
+	if s.Dictionary != nil {
+		words := s.Dictionary.Match(label)
+		if words.IsWord {
+			score += 20
+		} else if words.Compound {
+			score += 10
+		}
+	}
+	score += 15 * len(names.Categories(label, traits))
+	if reason == ReasonSuffix {
+		score += 10
+	}
+	return score
+}
+
+// digitSwaps 把每个数字换成其他数字
+func digitSwaps(label string) []string {
+	variants := make([]string, 0)
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			continue
+		}
+		for d := byte('0'); d <= '9'; d++ {
+			if d != label[i] {
+				variants = append(variants, label[:i]+string(d)+label[i+1:])
+			}
+		}
+	}
+	return variants
+}
+
+// plurals 英文单复数变体
+func plurals(label string) []string {
+	if label == "" || label[len(label)-1] < 'a' || label[len(label)-1] > 'z' {
+		return nil
+	}
+	switch {
+	case strings.HasSuffix(label, "es") && len(label) > 3:
+		return []string{label[:len(label)-2], label[:len(label)-1]}
+	case strings.HasSuffix(label, "s") && !strings.HasSuffix(label, "ss") && len(label) > 2:
+		return []string{label[:len(label)-1]}
+	case strings.HasSuffix(label, "s"), strings.HasSuffix(label, "x"), strings.HasSuffix(label, "ch"), strings.HasSuffix(label, "sh"):
+		return []string{label + "es"}
+	}
+	return []string{label + "s"}
+}
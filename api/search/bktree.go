@@ -0,0 +1,152 @@
+package search
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	DefaultFuzzyDistance = 2
+	MaxFuzzyDistance     = 3
+	// 模糊查询最多取的候选数，按距离从近到远，超出时结果标记为 truncated
+	MaxFuzzyHits = 1000
+)
+
+// FuzzyHit 一个模糊匹配的名称
+type FuzzyHit struct {
+	Id       int64
+	Label    string
+	Distance int
+	Score    float64
+}
+
+type bkNode struct {
+	label    []rune
+	ids      []int64
+	children map[int]*bkNode
+}
+
+// BKTree 按编辑距离组织的名称标签，用于模糊查询；同一标签的不同后缀共用一个节点
+type BKTree struct {
+	mu   sync.RWMutex
+	root *bkNode
+}
+
+func NewBKTree() *BKTree {
+	return &BKTree{}
+}
+
+// Insert 加入一个名称，label 为不含后缀的小写名称
+func (t *BKTree) Insert(label string, id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	runes := []rune(label)
+	if t.root == nil {
+		t.root = &bkNode{label: runes, ids: []int64{id}}
+		return
+	}
+	node := t.root
+	for {
+		d := Distance(node.label, runes)
+		if d == 0 {
+			node.ids = append(node.ids, id)
+			return
+		}
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*bkNode)
+			}
+			node.children[d] = &bkNode{label: runes, ids: []int64{id}}
+			return
+		}
+		node = child
+	}
+}
+
+// Search 返回与 label 编辑距离不超过 max 的全部名称，按距离、标签、id 升序
+func (t *BKTree) Search(label string, max int) []FuzzyHit {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	runes := []rune(label)
+	hits := make([]FuzzyHit, 0)
+	if t.root == nil {
+		return hits
+	}
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		d := Distance(node.label, runes)
+		if d <= max {
+			score := Similarity(d, len(node.label), len(runes))
+			label := string(node.label)
+			for _, id := range node.ids {
+				hits = append(hits, FuzzyHit{Id: id, Label: label, Distance: d, Score: score})
+			}
+		}
+		// 三角不等式：只有距离在 [d-max, d+max] 内的子树可能命中
+		for cd, child := range node.children {
+			if cd >= d-max && cd <= d+max {
+				stack = append(stack, child)
+			}
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Distance != hits[j].Distance {
+			return hits[i].Distance < hits[j].Distance
+		}
+		if hits[i].Label != hits[j].Label {
+			return hits[i].Label < hits[j].Label
+		}
+		return hits[i].Id < hits[j].Id
+	})
+	return hits
+}
+
+// Distance 两个标签按字符的编辑距离
+func Distance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Similarity 把编辑距离换算成 0-1 的相似度，1 表示完全相同
+func Similarity(distance, la, lb int) float64 {
+	longest := la
+	if lb > longest {
+		longest = lb
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(distance)/float64(longest)
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,195 @@
+package search
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 可统计的分面
+const (
+	FacetType     = "type"
+	FacetLength   = "length"
+	FacetCharset  = "charset"
+	FacetCategory = "category"
+	FacetHeight   = "height"
+	// 所有者地址类型，如 p2tr、p2pkh
+	FacetScriptType = "script_type"
+)
+
+var facetNames = map[string]bool{
+	FacetType:       true,
+	FacetLength:     true,
+	FacetCharset:    true,
+	FacetCategory:   true,
+	FacetHeight:     true,
+	FacetScriptType: true,
+}
+
+// Bucket 一个取值区间 [Min, Max]，Max 为 0 表示不设上限
+type Bucket struct {
+	Label string
+	Min   uint64
+	Max   uint64
+}
+
+// FacetCount 分面中一个取值的数量
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// LengthBuckets 长度分面的区间
+var LengthBuckets = []Bucket{
+	{"1", 1, 1},
+	{"2", 2, 2},
+	{"3", 3, 3},
+	{"4", 4, 4},
+	{"5", 5, 5},
+	{"6-10", 6, 10},
+	{"11+", 11, 0},
+}
+
+const (
+	// 高度分面默认每段的区块数
+	DefaultHeightStep = 10000
+	// 高度分面最多的区间数，超过时加大区间
+	MaxHeightBuckets = 50
+)
+
+// HeightStep 返回高度分面每段的区块数：step 不大于 0 时用默认值，
+// 区间数超过 MaxHeightBuckets 时成倍加大
+func HeightStep(min, max uint64, step int64) uint64 {
+	if step <= 0 {
+		step = DefaultHeightStep
+	}
+	n := uint64(step)
+	for max > min && (max-min)/n+1 > MaxHeightBuckets {
+		n *= 2
+	}
+	return n
+}
+
+// HeightBuckets 把 [min, max] 按 step 个区块分段，段首对齐到 step 的整数倍
+func HeightBuckets(min, max, step uint64) []Bucket {
+	buckets := make([]Bucket, 0)
+	if step == 0 || max < min {
+		return buckets
+	}
+	for start := min - min%step; start <= max; start += step {
+		end := start + step - 1
+		buckets = append(buckets, Bucket{fmt.Sprintf("%d-%d", start, end), start, end})
+	}
+	return buckets
+}
+
+// BucketCase 把字段的值映射为所在区间名的 SQL CASE 表达式，不在任何区间的为空串
+func BucketCase(field string, buckets []Bucket) string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	for _, bucket := range buckets {
+		if bucket.Max > 0 {
+			fmt.Fprintf(&b, " WHEN `%s` BETWEEN %d AND %d THEN '%s'", field, bucket.Min, bucket.Max, bucket.Label)
+		} else {
+			fmt.Fprintf(&b, " WHEN `%s` >= %d THEN '%s'", field, bucket.Min, bucket.Label)
+		}
+	}
+	b.WriteString(" ELSE '' END")
+	return b.String()
+}
+
+// FacetKey 由规范化后的查询条件和分面生成缓存 key，排序和分页不影响分面
+func (q Query) FacetKey(facets []string) string {
+	return hashKey(struct {
+		Filters []Filter
+		Facets  []string
+	}{q.Filters, facets})
+}
+
+// 分面统计用到的字段运算，与 beego orm 在 mysql 下生成的 SQL 一致
+var whereOperators = map[string]string{
+	"exact":       "= ?",
+	"gt":          "> ?",
+	"gte":         ">= ?",
+	"lt":          "< ?",
+	"lte":         "<= ?",
+	"icontains":   "LIKE ?",
+	"istartswith": "LIKE ?",
+	"iendswith":   "LIKE ?",
+}
+
+// Where 把查询条件转为 SQL 条件和参数，用于 QuerySeter 不支持的 GROUP BY 统计；
+// 没有条件时返回 "1 = 1"
+func (q Query) Where() (string, []interface{}, error) {
+	conds := make([]string, 0, len(q.Filters))
+	args := make([]interface{}, 0)
+	for _, f := range q.Filters {
+		field, op := f.Expr, "exact"
+		if i := strings.Index(f.Expr, "__"); i >= 0 {
+			field, op = f.Expr[:i], f.Expr[i+2:]
+		}
+		column := "`" + field + "`"
+
+		var cond string
+		switch {
+		case f.Raw != "":
+			cond = column + " " + f.Raw
+		case op == "in":
+			values := flatten(f.Args)
+			if len(values) == 0 {
+				return "", nil, fmt.Errorf("%s: no values", f.Expr)
+			}
+			cond = column + " IN (" + Placeholders(len(values)) + ")"
+			args = append(args, values...)
+		default:
+			sql, ok := whereOperators[op]
+			if !ok || len(f.Args) != 1 {
+				return "", nil, fmt.Errorf("%s: unsupported filter", f.Expr)
+			}
+			arg := f.Args[0]
+			if strings.HasPrefix(op, "i") {
+				param := strings.Replace(fmt.Sprint(arg), `%`, `\%`, -1)
+				switch op {
+				case "icontains":
+					param = "%" + param + "%"
+				case "istartswith":
+					param = param + "%"
+				case "iendswith":
+					param = "%" + param
+				}
+				arg = param
+			}
+			cond = column + " " + sql
+			args = append(args, arg)
+		}
+		if f.Exclude {
+			cond = "NOT (" + cond + ")"
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return "1 = 1", args, nil
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// Placeholders n 个以逗号分隔的 ? 占位符，用于 IN 条件
+func Placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// flatten 展开 in 条件的参数，参数可以是多个值或一个切片
+func flatten(args []interface{}) []interface{} {
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		v := reflect.ValueOf(arg)
+		if v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				values = append(values, v.Index(i).Interface())
+			}
+			continue
+		}
+		values = append(values, arg)
+	}
+	return values
+}
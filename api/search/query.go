@@ -0,0 +1,313 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"utils/bitcoin"
+	"utils/names"
+)
+
+// sorts 查询语句 sort: 的取值，- 表示降序
+var sorts = map[string]int{
+	"-id":          OrderIdDesc,
+	"id":           OrderIdAsc,
+	"content":      OrderContentAsc,
+	"-content":     OrderContentDesc,
+	"value":        OrderValueAsc,
+	"-value":       OrderValueDesc,
+	"length":       OrderShortest,
+	"emoji":        OrderEmojiCount,
+	"emoji_count":  OrderEmojiCount,
+	"-inscription": OrderIdDesc,
+	"inscription":  OrderIdAsc,
+}
+
+// term 查询语句中的一个词
+type term struct {
+	pos    int
+	text   string
+	negate bool
+	key    string
+	value  string
+}
+
+// ParseQuery 把查询语句编译到 Request 上，之后仍需 Validate 其余参数。
+// 语句与结构化参数合并：语句中出现的条件覆盖同一字段的参数，suffix 追加到 type 列表，
+// 语句没有涉及的参数保持不变。
+//
+// 语句由空格分隔的词组成，词前加 - 表示取反：
+//
+//	abc                 名称包含 abc，-abc 为不包含
+//	contains:abc        同上
+//	starts:ab ends:yz   开头、结尾
+//	len:3 len:3..5 len:>=3 len:<5
+//	suffix:sats,btc     后缀类型，可重复
+//	owner:bc1p...       所有者地址
+//	script:p2tr         所有者地址类型，taproot 同 script:p2tr
+//	minted:>800000 minted:800000..810000   铭刻区块高度
+//	category:10K charset:alnum pattern:ABBA wordlist:en emoji:zwj
+//	digits letters alnum emojis mixed      字符集
+//	has:digit has:letter has:emoji         含某类字符，-has: 为不含
+//	is:palindrome is:word is:compound is:prefixword is:skintone
+//	sort:-value         排序，见 sorts
+func ParseQuery(q string, r *Request) *Error {
+	for _, t := range tokenize(q) {
+		if err := t.apply(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tokenize(q string) []term {
+	terms := make([]term, 0)
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		t := term{pos: start, text: q[start:end]}
+		body := t.text
+		if strings.HasPrefix(body, "-") && len(body) > 1 {
+			t.negate = true
+			body = body[1:]
+		}
+		if i := strings.Index(body, ":"); i > 0 {
+			t.key, t.value = strings.ToLower(body[:i]), body[i+1:]
+		} else {
+			t.value = body
+		}
+		terms = append(terms, t)
+		start = -1
+	}
+	for i, r := range q {
+		if unicode.IsSpace(r) {
+			flush(i)
+		} else if start < 0 {
+			start = i
+		}
+	}
+	flush(len(q))
+	return terms
+}
+
+func (t term) fail(format string, args ...interface{}) *Error {
+	return &Error{Key: ErrQuery, Field: "q", Pos: t.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (t term) apply(r *Request) *Error {
+	if t.key != "" && t.value == "" {
+		return t.fail("%s: needs a value", t.key)
+	}
+
+	switch t.key {
+	case "":
+		return t.applyWord(r)
+	case "contains":
+		return t.setContains(r)
+	case "starts":
+		if t.negate {
+			return t.fail("starts: can not be negated")
+		}
+		r.StartWith = t.value
+	case "ends":
+		if t.negate {
+			return t.fail("ends: can not be negated")
+		}
+		r.EndWith = t.value
+	case "len":
+		min, max, err := parseRange(t.value)
+		if err != nil {
+			return t.fail("len: %s", err.Error())
+		}
+		r.MinWidth, r.MaxWidth = int(min), int(max)
+	case "minted":
+		min, max, err := parseRange(t.value)
+		if err != nil {
+			return t.fail("minted: %s", err.Error())
+		}
+		r.MinHeight, r.MaxHeight = min, max
+	case "suffix", "type":
+		for _, typ := range strings.Split(strings.ToLower(t.value), ",") {
+			if typ == "" {
+				return t.fail("%s: empty suffix", t.key)
+			}
+			r.TypeList = append(r.TypeList, typ)
+		}
+	case "owner":
+		r.Owner = t.value
+	case "script":
+		typ := strings.ToLower(t.value)
+		if typ == "taproot" {
+			typ = bitcoin.ScriptP2TR
+		}
+		if !scriptTypes[typ] {
+			return t.fail("script: unknown script type %q", t.value)
+		}
+		r.ScriptType = typ
+	case "category":
+		if _, ok := names.GetCategory(t.value); !ok {
+			return t.fail("category: unknown category %q", t.value)
+		}
+		r.Category = t.value
+	case "charset":
+		if !charsets[strings.ToLower(t.value)] {
+			return t.fail("charset: unknown charset %q", t.value)
+		}
+		r.Charset = strings.ToLower(t.value)
+	case "pattern":
+		r.Pattern = t.value
+	case "wordlist":
+		r.Wordlist = strings.ToLower(t.value)
+	case "emoji":
+		if !emojiClasses[strings.ToLower(t.value)] {
+			return t.fail("emoji: unknown emoji class %q", t.value)
+		}
+		r.EmojiClass = strings.ToLower(t.value)
+	case "has":
+		return t.applyHas(r)
+	case "is":
+		return t.applyIs(r)
+	case "sort":
+		order, ok := sorts[strings.ToLower(t.value)]
+		if !ok {
+			return t.fail("sort: unknown order %q", t.value)
+		}
+		r.OrderType = order
+	default:
+		return t.fail("unknown key %q", t.key)
+	}
+
+	if t.negate && t.key != "has" && t.key != "is" && t.key != "contains" {
+		return t.fail("%s: can not be negated", t.key)
+	}
+	return nil
+}
+
+// applyWord 不带 key 的词：字符集关键字，或名称包含的文本
+func (t term) applyWord(r *Request) *Error {
+	charset := map[string]string{
+		"digits":  names.CharsetDigit,
+		"letters": names.CharsetLetter,
+		"alnum":   names.CharsetAlnum,
+		"emojis":  names.CharsetEmoji,
+		"mixed":   names.CharsetMixed,
+	}[strings.ToLower(t.value)]
+	if charset != "" && !t.negate {
+		r.Charset = charset
+		return nil
+	}
+	return t.setContains(r)
+}
+
+func (t term) setContains(r *Request) *Error {
+	if t.negate {
+		if r.NotLike != "" {
+			return t.fail("only one excluded text is supported")
+		}
+		r.NotLike = t.value
+		return nil
+	}
+	if r.Name != "" {
+		return t.fail("only one search text is supported")
+	}
+	r.Name = t.value
+	return nil
+}
+
+func (t term) applyHas(r *Request) *Error {
+	v := !t.negate
+	switch strings.ToLower(t.value) {
+	case "digit", "digits":
+		r.HasDigit = &v
+	case "letter", "letters":
+		r.HasLetter = &v
+	case "emoji", "emojis":
+		r.HasEmoji = &v
+	default:
+		return t.fail("has: unknown class %q, want digit, letter or emoji", t.value)
+	}
+	return nil
+}
+
+func (t term) applyIs(r *Request) *Error {
+	v := !t.negate
+	switch strings.ToLower(t.value) {
+	case "palindrome":
+		r.Palindrome = &v
+	case "word":
+		r.IsWord = &v
+	case "compound":
+		r.Compound = &v
+	case "prefixword":
+		r.WordPrefix = &v
+	case "skintone":
+		r.SkinTone = &v
+	default:
+		return t.fail("is: unknown flag %q", t.value)
+	}
+	return nil
+}
+
+// parseRange 解析 3、3..5、>=3、>3、<=5、<5，返回闭区间，0 表示不限制；
+// 上界为 0 的区间(如 <1、0..0)是空区间，返回错误，避免被当成不限制
+func parseRange(s string) (uint64, uint64, error) {
+	if i := strings.Index(s, ".."); i >= 0 {
+		min, err := parseNumber(s[:i])
+		if err != nil {
+			return 0, 0, err
+		}
+		max, err := parseNumber(s[i+2:])
+		if err != nil {
+			return 0, 0, err
+		}
+		if min > max || max == 0 {
+			return 0, 0, fmt.Errorf("empty range %s", s)
+		}
+		return min, max, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		n, err := parseNumber(s[len(op):])
+		if err != nil {
+			return 0, 0, err
+		}
+		switch op {
+		case ">=":
+			return n, 0, nil
+		case ">":
+			return n + 1, 0, nil
+		case "<=":
+			if n == 0 {
+				return 0, 0, fmt.Errorf("empty range %s", s)
+			}
+			return 0, n, nil
+		}
+		if n <= 1 {
+			return 0, 0, fmt.Errorf("empty range %s", s)
+		}
+		return 0, n - 1, nil
+	}
+
+	n, err := parseNumber(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	if n == 0 {
+		return 0, 0, fmt.Errorf("empty range %s", s)
+	}
+	return n, n, nil
+}
+
+func parseNumber(s string) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,657 @@
+package search
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"utils/bitcoin"
+	"utils/names"
+
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 500
+)
+
+// orderType 排序方式
+const (
+	OrderIdDesc = iota
+	OrderIdAsc
+	OrderContentAsc
+	OrderContentDesc
+	OrderValueAsc
+	OrderValueDesc
+	OrderShortest
+	OrderEmojiCount
+)
+
+// mode 查询方式
+const (
+	ModeSubstring = ""
+	ModeFuzzy     = "fuzzy"
+)
+
+// wordsType 字符类型
+const (
+	WordsDigit = iota
+	WordsLetter
+	WordsEmoji
+)
+
+// 参数校验错误，对应 conf/*.ini 里的错误码
+const (
+	ErrParam     = "参数错误"
+	ErrPage      = "无效的分页参数"
+	ErrOrderType = "无效的排序方式"
+	ErrWidth     = "无效的长度范围"
+	ErrWordsType = "无效的字符类型"
+	ErrCategory  = "无效的分类"
+	ErrCursor    = "无效的游标"
+	ErrCharset   = "无效的字符集"
+	ErrPattern   = "无效的模式"
+	ErrWordlist  = "无效的词库"
+	ErrEmoji     = "无效的emoji分类"
+	ErrQuery     = "无效的查询语句"
+	ErrAddress   = "无效的地址"
+)
+
+// order 排序字段，相同值按 id 同方向排序保证分页稳定
+type order struct {
+	field string
+	desc  bool
+}
+
+var orders = map[int]order{
+	OrderIdDesc:      {"id", true},
+	OrderIdAsc:       {"id", false},
+	OrderContentAsc:  {"content", false},
+	OrderContentDesc: {"content", true},
+	OrderValueAsc:    {"value", false},
+	OrderValueDesc:   {"value", true},
+	OrderShortest:    {"length", false},
+	OrderEmojiCount:  {"emoji_count", false},
+}
+
+var wordsTypes = map[int]string{
+	WordsDigit:  names.CharsetDigit,
+	WordsLetter: names.CharsetLetter,
+	WordsEmoji:  names.CharsetEmoji,
+}
+
+var emojiClasses = map[string]bool{
+	names.EmojiSingle:   true,
+	names.EmojiSequence: true,
+	names.EmojiZWJ:      true,
+}
+
+var charsets = map[string]bool{
+	names.CharsetDigit:  true,
+	names.CharsetLetter: true,
+	names.CharsetAlnum:  true,
+	names.CharsetEmoji:  true,
+	names.CharsetMixed:  true,
+}
+
+var scriptTypes = func() map[string]bool {
+	m := make(map[string]bool, len(bitcoin.ScriptTypes))
+	for _, t := range bitcoin.ScriptTypes {
+		m[t] = true
+	}
+	return m
+}()
+
+// CategoryTable 名称分类表，由 controllers 按表前缀设置
+var CategoryTable = "domain_category"
+
+// WordTable 名称词库表，由 controllers 按表前缀设置
+var WordTable = "domain_word"
+
+// Dictionary 已加载的词库，用于校验 wordlist 参数，由 controllers 设置
+var Dictionary *names.Dictionary
+
+// Network 比特币网络，用于校验 owner 参数，由 controllers 按配置设置
+var Network, _ = bitcoin.Network(bitcoin.Mainnet)
+
+// Request 域名查询参数
+type Request struct {
+	Name      string   `json:"name"`
+	PageNum   int      `json:"pageNum"`
+	PageSize  int      `json:"pageSize"`
+	Category  string   `json:"category"`
+	TypeList  []string `json:"typeList"`
+	OrderType int      `json:"orderType"`
+	StartWith string   `json:"startWith"`
+	EndWith   string   `json:"endWith"`
+	MinWidth  int      `json:"minWidth"`
+	MaxWidth  int      `json:"maxWidth"`
+	NotLike   string   `json:"notLike"`
+	WordsType *int     `json:"wordsType"`
+	Cursor    string   `json:"cursor"`
+
+	Charset    string `json:"charset"`
+	HasDigit   *bool  `json:"hasDigit"`
+	HasLetter  *bool  `json:"hasLetter"`
+	HasEmoji   *bool  `json:"hasEmoji"`
+	Palindrome *bool  `json:"palindrome"`
+	MinRun     int    `json:"minRun"`
+	Pattern    string `json:"pattern"`
+
+	IsWord     *bool  `json:"isWord"`
+	WordPrefix *bool  `json:"wordPrefix"`
+	Compound   *bool  `json:"compound"`
+	Wordlist   string `json:"wordlist"`
+
+	EmojiClass string `json:"emojiClass"`
+	SkinTone   *bool  `json:"skinTone"`
+	MinEmoji   int    `json:"minEmoji"`
+	MaxEmoji   int    `json:"maxEmoji"`
+
+	Mode        string `json:"mode"`
+	MaxDistance int    `json:"maxDistance"`
+
+	Owner      string `json:"owner"`
+	ScriptType string `json:"scriptType"`
+	MinHeight  uint64 `json:"minHeight"`
+	MaxHeight  uint64 `json:"maxHeight"`
+	// 查询语句，如 len:3 suffix:sats digits -contains:0 sort:-value，见 ParseQuery
+	Q string `json:"q"`
+	// 需要统计的分面，见 FacetType 等
+	Facets []string `json:"facets"`
+
+	pattern *names.Pattern
+	suffix  string
+
+	after *Keyset
+}
+
+// Error 参数校验错误，Key 为 i18n 的错误码，Field 为出错的参数；
+// 查询语句出错时 Pos 为出错词在 q 中的位置(从 0 开始)，Message 为原因
+type Error struct {
+	Key     string
+	Field   string
+	Pos     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s: %s at %d: %s", e.Key, e.Field, e.Pos, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Key, e.Field)
+}
+
+// Data 返回给前端的错误详情
+func (e *Error) Data() interface{} {
+	if e.Message == "" {
+		return e.Field
+	}
+	return struct {
+		Field   string `json:"field"`
+		Pos     int    `json:"pos"`
+		Message string `json:"message"`
+	}{e.Field, e.Pos, e.Message}
+}
+
+// Filter 一个查询条件
+type Filter struct {
+	Expr    string
+	Args    []interface{}
+	Exclude bool
+	Raw     string
+}
+
+// Keyset 游标位置，即上一页最后一条的排序值和 id
+type Keyset struct {
+	OrderType int    `json:"o"`
+	Value     string `json:"v"`
+	Id        int64  `json:"i"`
+}
+
+// Query 由 Request 生成的查询
+type Query struct {
+	Filters []Filter
+	OrderBy []string
+	After   *Keyset
+	Limit   int
+	Offset  int
+}
+
+// Validate 校验参数并填充默认值
+func (r *Request) Validate() *Error {
+	if r.PageNum == 0 {
+		r.PageNum = 1
+	}
+	if r.Q != "" {
+		if err := ParseQuery(r.Q, r); err != nil {
+			return err
+		}
+	}
+
+	// xn-- 开头的 punycode 先解码，库里存的是 Unicode
+	r.Name = names.ToUnicode(r.Name)
+	if r.PageSize == 0 {
+		r.PageSize = DefaultPageSize
+	}
+	if r.PageNum < 0 {
+		return &Error{Key: ErrPage, Field: "pageNum"}
+	}
+	if r.PageSize < 0 {
+		return &Error{Key: ErrPage, Field: "pageSize"}
+	}
+	if r.PageSize > MaxPageSize {
+		r.PageSize = MaxPageSize
+	}
+
+	if _, ok := orders[r.OrderType]; !ok {
+		return &Error{Key: ErrOrderType, Field: "orderType"}
+	}
+
+	if r.MinWidth < 0 {
+		return &Error{Key: ErrWidth, Field: "minWidth"}
+	}
+	if r.MaxWidth < 0 || (r.MaxWidth > 0 && r.MinWidth > r.MaxWidth) {
+		return &Error{Key: ErrWidth, Field: "maxWidth"}
+	}
+
+	if r.WordsType != nil {
+		if _, ok := wordsTypes[*r.WordsType]; !ok {
+			return &Error{Key: ErrWordsType, Field: "wordsType"}
+		}
+	}
+
+	if r.Category != "" {
+		if _, ok := names.GetCategory(r.Category); !ok {
+			return &Error{Key: ErrCategory, Field: "category"}
+		}
+	}
+
+	if r.Charset != "" && !charsets[r.Charset] {
+		return &Error{Key: ErrCharset, Field: "charset"}
+	}
+
+	if r.EmojiClass != "" && !emojiClasses[r.EmojiClass] {
+		return &Error{Key: ErrEmoji, Field: "emojiClass"}
+	}
+
+	if r.MinEmoji < 0 {
+		return &Error{Key: ErrWidth, Field: "minEmoji"}
+	}
+	if r.MaxEmoji < 0 || (r.MaxEmoji > 0 && r.MinEmoji > r.MaxEmoji) {
+		return &Error{Key: ErrWidth, Field: "maxEmoji"}
+	}
+
+	if r.Owner != "" {
+		owner, err := bitcoin.Canonical(r.Owner, Network)
+		if err != nil {
+			return &Error{Key: ErrAddress, Field: "owner", Message: err.Error()}
+		}
+		r.Owner = owner
+	}
+	if r.ScriptType != "" && !scriptTypes[r.ScriptType] {
+		return &Error{Key: ErrParam, Field: "scriptType"}
+	}
+
+	if r.MaxHeight > 0 && r.MinHeight > r.MaxHeight {
+		return &Error{Key: ErrParam, Field: "maxHeight"}
+	}
+
+	if r.MinRun < 0 {
+		return &Error{Key: ErrParam, Field: "minRun"}
+	}
+
+	if r.Pattern != "" {
+		// AABB.sats: 后缀部分作为类型过滤
+		label := r.Pattern
+		if i := strings.Index(label, "."); i >= 0 {
+			label, r.suffix = label[:i], strings.ToLower(label[i+1:])
+			if r.suffix == "" {
+				return &Error{Key: ErrPattern, Field: "pattern"}
+			}
+		}
+		pattern, err := names.CompilePattern(label)
+		if err != nil {
+			return &Error{Key: ErrPattern, Field: "pattern"}
+		}
+		r.pattern = pattern
+	}
+
+	if r.Wordlist != "" && (Dictionary == nil || !Dictionary.Has(r.Wordlist)) {
+		return &Error{Key: ErrWordlist, Field: "wordlist"}
+	}
+
+	for _, t := range r.TypeList {
+		if t == "" {
+			return &Error{Key: ErrParam, Field: "typeList"}
+		}
+	}
+
+	switch r.Mode {
+	case ModeSubstring:
+	case ModeFuzzy:
+		r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+		if r.Name == "" {
+			return &Error{Key: ErrParam, Field: "name"}
+		}
+		if r.MaxDistance == 0 {
+			r.MaxDistance = DefaultFuzzyDistance
+		}
+		if r.MaxDistance < 0 || r.MaxDistance > MaxFuzzyDistance {
+			return &Error{Key: ErrParam, Field: "maxDistance"}
+		}
+		// 模糊查询按相似度排序，不支持游标
+		if r.Cursor != "" {
+			return &Error{Key: ErrCursor, Field: "cursor"}
+		}
+	default:
+		return &Error{Key: ErrParam, Field: "mode"}
+	}
+
+	if len(r.Facets) > 0 {
+		if r.Fuzzy() {
+			return &Error{Key: ErrParam, Field: "facets"}
+		}
+		seen := make(map[string]bool, len(r.Facets))
+		facets := make([]string, 0, len(r.Facets))
+		for _, f := range r.Facets {
+			if !facetNames[f] {
+				return &Error{Key: ErrParam, Field: "facets"}
+			}
+			if !seen[f] {
+				seen[f] = true
+				facets = append(facets, f)
+			}
+		}
+		sort.Strings(facets)
+		r.Facets = facets
+	}
+
+	if r.Cursor != "" {
+		after, err := decodeCursor(r.Cursor)
+		if err != nil || after.OrderType != r.OrderType {
+			return &Error{Key: ErrCursor, Field: "cursor"}
+		}
+		if orders[r.OrderType].field != "id" && orders[r.OrderType].field != "content" {
+			if _, err := strconv.ParseUint(after.Value, 10, 64); err != nil {
+				return &Error{Key: ErrCursor, Field: "cursor"}
+			}
+		}
+		r.after = after
+	}
+	return nil
+}
+
+// Fuzzy 是否为模糊查询，此时 name 由调用方按编辑距离匹配，不生成 content 条件
+func (r *Request) Fuzzy() bool {
+	return r.Mode == ModeFuzzy
+}
+
+// SortField 当前排序字段，用于生成 next_cursor
+func (r *Request) SortField() string {
+	return orders[r.OrderType].field
+}
+
+// NextCursor 由本页最后一条生成下一页的游标
+func (r *Request) NextCursor(id int64, value interface{}) string {
+	after := Keyset{OrderType: r.OrderType, Value: fmt.Sprint(value), Id: id}
+	data, _ := json.Marshal(after)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (*Keyset, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	after := &Keyset{}
+	if err := json.Unmarshal(data, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Build 生成查询条件，调用前需先 Validate
+func (r *Request) Build() Query {
+	q := Query{}
+	if r.Name != "" && !r.Fuzzy() {
+		q.filter("content__icontains", r.Name)
+	}
+
+	if len(r.TypeList) > 0 {
+		q.filter("type__in", r.TypeList)
+	}
+
+	if r.StartWith != "" {
+		q.filter("content__istartswith", r.StartWith)
+	}
+
+	if r.EndWith != "" {
+		q.filter("content__iendswith", r.EndWith)
+	}
+
+	if r.MinWidth > 0 {
+		q.filter("length__gte", r.MinWidth)
+	}
+
+	if r.MaxWidth > 0 {
+		q.filter("length__lte", r.MaxWidth)
+	}
+
+	if r.WordsType != nil {
+		q.filter("charset", wordsTypes[*r.WordsType])
+	}
+
+	if r.Charset != "" {
+		q.filter("charset", r.Charset)
+	}
+
+	if r.Category != "" {
+		// category 已校验为已知分类名，可直接拼入子查询
+		q.raw("id", fmt.Sprintf("IN (SELECT domain_id FROM %s WHERE category = '%s')", CategoryTable, r.Category))
+	}
+
+	if r.HasDigit != nil {
+		q.filter("has_digit", *r.HasDigit)
+	}
+
+	if r.HasLetter != nil {
+		q.filter("has_letter", *r.HasLetter)
+	}
+
+	if r.HasEmoji != nil {
+		q.filter("has_emoji", *r.HasEmoji)
+	}
+
+	if r.Palindrome != nil {
+		q.filter("palindrome", *r.Palindrome)
+	}
+
+	if r.MinRun > 0 {
+		q.filter("max_run__gte", r.MinRun)
+	}
+
+	if r.Owner != "" {
+		q.filter("owner", r.Owner)
+	}
+	if r.ScriptType != "" {
+		q.filter("script_type", r.ScriptType)
+	}
+
+	if r.MinHeight > 0 {
+		q.filter("height__gte", r.MinHeight)
+	}
+
+	if r.MaxHeight > 0 {
+		q.filter("height__lte", r.MaxHeight)
+	}
+
+	if r.EmojiClass != "" {
+		q.filter("emoji_class", r.EmojiClass)
+	}
+
+	if r.SkinTone != nil {
+		q.filter("skin_tone", *r.SkinTone)
+	}
+
+	if r.MinEmoji > 0 {
+		q.filter("emoji_count__gte", r.MinEmoji)
+	}
+
+	if r.MaxEmoji > 0 {
+		q.filter("emoji_count__lte", r.MaxEmoji)
+	}
+
+	if r.IsWord != nil {
+		q.filter("is_word", *r.IsWord)
+	}
+
+	if r.WordPrefix != nil {
+		q.filter("word_prefix", *r.WordPrefix)
+	}
+
+	if r.Compound != nil {
+		q.filter("compound", *r.Compound)
+	}
+
+	if r.Wordlist != "" {
+		// wordlist 已校验为已加载的词库名，可直接拼入子查询
+		q.raw("id", fmt.Sprintf("IN (SELECT domain_id FROM %s WHERE list = '%s')", WordTable, r.Wordlist))
+	}
+
+	if r.pattern != nil {
+		r.buildPattern(&q)
+	}
+
+	if r.NotLike != "" {
+		q.exclude("content__icontains", r.NotLike)
+	}
+
+	o := orders[r.OrderType]
+	q.OrderBy = []string{o.expr(o.field)}
+	if o.field != "id" {
+		q.OrderBy = append(q.OrderBy, o.expr("id"))
+	}
+
+	q.Limit = r.PageSize
+	if r.after != nil {
+		q.After = r.after
+	} else {
+		q.Offset = (r.PageNum - 1) * r.PageSize
+	}
+	return q
+}
+
+// buildPattern 模式转为索引列上的条件；掩码和 LIKE 只含 NLEO_、a-z0-9- 等字符，可直接拼入
+func (r *Request) buildPattern(q *Query) {
+	p := r.pattern
+	if r.suffix != "" {
+		q.filter("type", r.suffix)
+	}
+	q.filter("length", p.Length)
+
+	if strings.Contains(p.Mask, "_") {
+		if strings.Trim(p.Mask, "_") != "" {
+			q.raw("mask", fmt.Sprintf("LIKE '%s'", p.Mask))
+		}
+	} else {
+		q.filter("mask", p.Mask)
+	}
+
+	if len(p.Shapes) == 1 {
+		q.filter("shape", p.Shapes[0])
+	} else if len(p.Shapes) > 1 {
+		q.filter("shape__in", p.Shapes)
+	}
+
+	if p.Like != "" {
+		q.raw("content", fmt.Sprintf("LIKE '%s'", p.Like))
+	}
+}
+
+// Apply 把条件加到 QuerySeter 上，排序和分页由调用方处理
+func (q Query) Apply(qs orm.QuerySeter) orm.QuerySeter {
+	for _, f := range q.Filters {
+		switch {
+		case f.Raw != "":
+			qs = qs.FilterRaw(f.Expr, f.Raw)
+		case f.Exclude:
+			qs = qs.Exclude(f.Expr, f.Args...)
+		default:
+			qs = qs.Filter(f.Expr, f.Args...)
+		}
+	}
+	return qs
+}
+
+// Page 加上游标条件、排序和分页，需在 Count 之后调用
+func (q Query) Page(qs orm.QuerySeter) orm.QuerySeter {
+	if q.After != nil {
+		cond := qs.GetCond()
+		if cond == nil {
+			cond = orm.NewCondition()
+		}
+		qs = qs.SetCond(cond.AndCond(q.After.cond()))
+	}
+	return qs.OrderBy(q.OrderBy...).Limit(q.Limit, q.Offset)
+}
+
+// CacheKey 由规范化后的完整查询和分面生成缓存 key，同一结果的不同写法得到相同的 key
+func (q Query) CacheKey(facets []string) string {
+	return hashKey(struct {
+		Query  Query
+		Facets []string
+	}{q, facets})
+}
+
+func hashKey(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cond 排在游标之后：field 越过游标值，或值相同且 id 越过游标 id
+func (k *Keyset) cond() *orm.Condition {
+	o := orders[k.OrderType]
+	op := "__gt"
+	if o.desc {
+		op = "__lt"
+	}
+
+	cond := orm.NewCondition()
+	if o.field == "id" {
+		return cond.And("id"+op, k.Id)
+	}
+
+	var value interface{} = k.Value
+	if o.field != "content" {
+		value, _ = strconv.ParseUint(k.Value, 10, 64)
+	}
+	beyond := cond.And(o.field+op, value)
+	tie := cond.And(o.field, value).And("id"+op, k.Id)
+	return cond.AndCond(beyond.OrCond(tie))
+}
+
+func (o order) expr(field string) string {
+	if o.desc {
+		return "-" + field
+	}
+	return field
+}
+
+func (q *Query) filter(expr string, args ...interface{}) {
+	q.Filters = append(q.Filters, Filter{Expr: expr, Args: args})
+}
+
+func (q *Query) exclude(expr string, args ...interface{}) {
+	q.Filters = append(q.Filters, Filter{Expr: expr, Args: args, Exclude: true})
+}
+
+func (q *Query) raw(expr string, sql string) {
+	q.Filters = append(q.Filters, Filter{Expr: expr, Raw: sql})
+}
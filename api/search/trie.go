@@ -0,0 +1,198 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultSuggestSize = 10
+	MaxSuggestSize     = 50
+)
+
+// Suggestion 一条补全结果
+type Suggestion struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Id     int64  `json:"id"`
+	Length int    `json:"length"`
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// 以该节点结尾的名称，只有大小写不同的名称共用一个节点
+	entries []Suggestion
+}
+
+// Trie 按后缀类型分开的名称前缀树，用于输入补全
+type Trie struct {
+	mu     sync.RWMutex
+	roots  map[string]*trieNode
+	lastId int64
+	size   int
+	// 首次全量加载是否完成
+	loaded bool
+}
+
+func NewTrie() *Trie {
+	return &Trie{roots: make(map[string]*trieNode), lastId: -1}
+}
+
+// Insert 加入一个名称，label 为不含后缀的小写名称，同名的名称替换已有的
+func (t *Trie) Insert(label string, s Suggestion) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	node, ok := t.roots[s.Type]
+	if !ok {
+		node = &trieNode{}
+		t.roots[s.Type] = node
+	}
+	for _, r := range label {
+		if node.children == nil {
+			node.children = make(map[rune]*trieNode)
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	i := 0
+	for i < len(node.entries) && node.entries[i].Name != s.Name {
+		i++
+	}
+	if i == len(node.entries) {
+		node.entries = append(node.entries, s)
+		t.size++
+	} else {
+		node.entries[i] = s
+	}
+	if s.Id > t.lastId {
+		t.lastId = s.Id
+	}
+}
+
+// Lookup 查找后缀类型 typ 下的名称 label，大小写须与注册的名称一致
+func (t *Trie) Lookup(label string, typ string) (Suggestion, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	node := t.roots[typ].find(strings.ToLower(label))
+	if node == nil {
+		return Suggestion{}, false
+	}
+	name := label + "." + typ
+	for _, entry := range node.entries {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return Suggestion{}, false
+}
+
+// SetLoaded 标记首次全量加载完成
+func (t *Trie) SetLoaded() {
+	t.mu.Lock()
+	t.loaded = true
+	t.mu.Unlock()
+}
+
+// Loaded 首次全量加载是否完成，完成前树里缺少已注册的名称
+func (t *Trie) Loaded() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.loaded
+}
+
+// LastId 已加入的最大铭文序号，用于增量加载
+func (t *Trie) LastId() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lastId
+}
+
+// Size 已加入的名称数
+func (t *Trie) Size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// Suggest 返回以 prefix 开头的前 n 个名称，按长度、铭文序号升序；
+// types 为空时在全部后缀中查找
+func (t *Trie) Suggest(prefix string, types []string, n int) []Suggestion {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	level := make([]*trieNode, 0)
+	if len(types) == 0 {
+		for typ := range t.roots {
+			types = append(types, typ)
+		}
+	}
+	for _, typ := range types {
+		if node := t.roots[typ].find(prefix); node != nil {
+			level = append(level, node)
+		}
+	}
+
+	// 按层遍历，越浅的名称越短；凑够 n 个后处理完当前层即可停止
+	list := make([]Suggestion, 0, n)
+	for len(level) > 0 && len(list) < n {
+		next := make([]*trieNode, 0)
+		for _, node := range level {
+			list = append(list, node.entries...)
+			for _, child := range node.children {
+				next = append(next, child)
+			}
+		}
+		level = next
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Length != list[j].Length {
+			return list[i].Length < list[j].Length
+		}
+		return list[i].Id < list[j].Id
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+func (node *trieNode) find(prefix string) *trieNode {
+	for _, r := range prefix {
+		if node == nil {
+			return nil
+		}
+		node = node.children[r]
+	}
+	return node
+}
+
+// SplitSuggest 拆分输入："abc.sa" 为前缀 abc，后缀类型以 sa 开头
+func SplitSuggest(q string) (prefix string, typePrefix string, hasType bool) {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if i := strings.LastIndex(q, "."); i >= 0 {
+		return q[:i], q[i+1:], true
+	}
+	return q, "", false
+}
+
+// Types 以 typePrefix 开头的后缀类型
+func (t *Trie) Types(typePrefix string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	types := make([]string, 0)
+	for typ := range t.roots {
+		if strings.HasPrefix(typ, typePrefix) {
+			types = append(types, typ)
+		}
+	}
+	return types
+}
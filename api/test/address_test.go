@@ -0,0 +1,13 @@
+package test
+
+import (
+	"api/search"
+	"testing"
+)
+
+func TestQueryOwnerCanonical(t *testing.T) {
+	req := search.Request{Owner: "BC1QQYYQ79SAYS4NYW2QGA892HRRDFCHSLUXW64F9C"}
+	if err := req.Validate(); err != nil || req.Owner != "bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c" {
+		t.Errorf("owner = %q %v, want lowercase", req.Owner, err)
+	}
+}
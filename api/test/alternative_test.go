@@ -0,0 +1,69 @@
+package test
+
+import (
+	"api/search"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAlternatives(t *testing.T) {
+	taken := map[string]bool{"moon.sats": true, "moon.btc": true, "moons.sats": true}
+	source := &search.AlternativeSource{
+		Types: []string{"sats", "btc", "ord"},
+		Normalize: func(name string) (string, bool) {
+			name = strings.ToLower(name)
+			return name, strings.Count(name, ".") == 1
+		},
+		Taken: func(label string, typ string) bool {
+			return taken[label+"."+typ]
+		},
+		Dictionary: search.Dictionary,
+	}
+
+	list := source.Alternatives("moon", "sats", 100)
+	got := make(map[string]string)
+	for i, a := range list {
+		got[a.Name] = a.Reason
+		if taken[a.Name] || a.Name == "moon.sats" {
+			t.Errorf("%s is taken", a.Name)
+		}
+		if i > 0 && list[i-1].Score < a.Score {
+			t.Errorf("not sorted by score: %+v before %+v", list[i-1], a)
+		}
+	}
+	for name, reason := range map[string]string{"moon.ord": search.ReasonSuffix, "themoon.sats": search.ReasonPrefix, "moonhq.sats": search.ReasonAffix} {
+		if got[name] != reason {
+			t.Errorf("%s: reason = %q, want %q", name, got[name], reason)
+		}
+	}
+	if _, ok := got["moons.sats"]; ok {
+		t.Errorf("plural moons.sats is taken")
+	}
+	if list[0].Name != "moon.ord" {
+		t.Errorf("best = %+v, want moon.ord", list[0])
+	}
+
+	if n := len(source.Alternatives("moon", "sats", 3)); n != 3 {
+		t.Errorf("limit: len = %d", n)
+	}
+}
+
+func TestAlternativeDigits(t *testing.T) {
+	source := &search.AlternativeSource{
+		Normalize: func(name string) (string, bool) { return name, true },
+		Taken:     func(label string, typ string) bool { return false },
+	}
+	got := make([]string, 0)
+	for _, a := range source.Alternatives("07", "sats", 100) {
+		if a.Reason == search.ReasonDigit {
+			got = append(got, a.Name)
+		}
+	}
+	if len(got) != 18 {
+		t.Errorf("digit swaps = %v", got)
+	}
+	if !reflect.DeepEqual(got[:1], []string{"00.sats"}) {
+		t.Errorf("best digit swap = %v", got[:1])
+	}
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"api/search"
+	"reflect"
+	"testing"
+)
+
+func TestHeightBuckets(t *testing.T) {
+	cases := []struct {
+		min, max, step uint64
+		want           []search.Bucket
+	}{
+		{800123, 815000, 10000, []search.Bucket{
+			{Label: "800000-809999", Min: 800000, Max: 809999},
+			{Label: "810000-819999", Min: 810000, Max: 819999},
+		}},
+		{5, 5, 10, []search.Bucket{{Label: "0-9", Min: 0, Max: 9}}},
+		{10, 5, 10, []search.Bucket{}},
+		{1, 5, 0, []search.Bucket{}},
+	}
+	for _, c := range cases {
+		got := search.HeightBuckets(c.min, c.max, c.step)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("HeightBuckets(%d, %d, %d) = %+v, want %+v", c.min, c.max, c.step, got, c.want)
+		}
+	}
+}
+
+func TestFacetValidate(t *testing.T) {
+	cases := []struct {
+		facets []string
+		mode   string
+		want   []string
+		ok     bool
+	}{
+		{[]string{"type", "length"}, "", []string{"length", "type"}, true},
+		{[]string{"height", "height", "charset"}, "", []string{"charset", "height"}, true},
+		{[]string{"owner"}, "", nil, false},
+		{[]string{"type"}, search.ModeFuzzy, nil, false},
+	}
+	for _, c := range cases {
+		req := search.Request{Name: "abc", Facets: c.facets, Mode: c.mode}
+		err := req.Validate()
+		if (err == nil) != c.ok {
+			t.Errorf("%v: err = %v, want ok %v", c.facets, err, c.ok)
+			continue
+		}
+		if c.ok && !reflect.DeepEqual(req.Facets, c.want) {
+			t.Errorf("%v: facets = %v, want %v", c.facets, req.Facets, c.want)
+		}
+	}
+}
+
+func TestFacetKey(t *testing.T) {
+	a := search.Request{Q: "len:3 suffix:sats", PageSize: 10, Facets: []string{"type", "length"}}
+	b := search.Request{Q: "suffix:sats len:3", PageSize: 50, OrderType: search.OrderValueDesc, Facets: []string{"length", "type"}}
+	c := search.Request{Q: "len:4 suffix:sats", Facets: []string{"type", "length"}}
+	for _, r := range []*search.Request{&a, &b, &c} {
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keyA := a.Build().FacetKey(a.Facets)
+	if keyB := b.Build().FacetKey(b.Facets); keyA != keyB {
+		t.Errorf("same filters, different keys %s %s", keyA, keyB)
+	}
+	if keyC := c.Build().FacetKey(c.Facets); keyA == keyC {
+		t.Errorf("different filters, same key %s", keyA)
+	}
+	if keyA == a.Build().FacetKey([]string{"type"}) {
+		t.Errorf("different facets, same key %s", keyA)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	build := func(r search.Request) search.Query {
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return r.Build()
+	}
+	a := build(search.Request{Q: "len:3 suffix:sats sort:-value"})
+	b := build(search.Request{TypeList: []string{"sats"}, MinWidth: 3, MaxWidth: 3, OrderType: search.OrderValueDesc})
+	if a.CacheKey(nil) != b.CacheKey(nil) {
+		t.Errorf("query language and fields give different keys")
+	}
+	c := build(search.Request{Q: "len:3 suffix:sats sort:-value", PageNum: 2})
+	if a.CacheKey(nil) == c.CacheKey(nil) {
+		t.Errorf("different pages, same key")
+	}
+	if a.CacheKey(nil) == a.CacheKey([]string{"type"}) {
+		t.Errorf("different facets, same key")
+	}
+}
+
+func TestHeightStep(t *testing.T) {
+	cases := []struct {
+		min, max uint64
+		step     int64
+		want     uint64
+	}{
+		{800000, 815000, 10000, 10000},
+		{800000, 815000, 0, search.DefaultHeightStep},
+		{800000, 815000, -5, search.DefaultHeightStep},
+		{0, 800000, 10000, 20000},
+		{5, 5, 1, 1},
+	}
+	for _, c := range cases {
+		if got := search.HeightStep(c.min, c.max, c.step); got != c.want {
+			t.Errorf("HeightStep(%d, %d, %d) = %d, want %d", c.min, c.max, c.step, got, c.want)
+		}
+	}
+}
+
+func TestQueryWhere(t *testing.T) {
+	no := false
+	req := search.Request{Name: "a%b", TypeList: []string{"sats", "btc"}, MinWidth: 3, HasDigit: &no}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	where, args, err := req.Build().Where()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantWhere := "`content` LIKE ? AND `type` IN (?, ?) AND `length` >= ? AND `has_digit` = ?"
+	wantArgs := []interface{}{`%a\%b%`, "sats", "btc", 3, false}
+	if where != wantWhere || !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Where() = %q %v, want %q %v", where, args, wantWhere, wantArgs)
+	}
+
+	if where, args, err := (search.Query{}).Where(); err != nil || where != "1 = 1" || len(args) != 0 {
+		t.Errorf("empty Where() = %q %v %v", where, args, err)
+	}
+}
+
+func TestBucketCase(t *testing.T) {
+	buckets := []search.Bucket{
+		{Label: "1", Min: 1, Max: 1},
+		{Label: "2-5", Min: 2, Max: 5},
+		{Label: "6+", Min: 6},
+	}
+	want := "CASE WHEN `length` BETWEEN 1 AND 1 THEN '1' WHEN `length` BETWEEN 2 AND 5 THEN '2-5' WHEN `length` >= 6 THEN '6+' ELSE '' END"
+	if got := search.BucketCase("length", buckets); got != want {
+		t.Errorf("BucketCase = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"api/search"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		d    int
+	}{
+		{"bitcoin", "bitcon", 1},
+		{"bitcoin", "bitcoin", 0},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"🐶🐱", "🐶", 1},
+	}
+	for _, c := range cases {
+		if d := search.Distance([]rune(c.a), []rune(c.b)); d != c.d {
+			t.Errorf("%s/%s: distance = %d, want %d", c.a, c.b, d, c.d)
+		}
+	}
+}
+
+func TestBKTreeSearch(t *testing.T) {
+	tree := search.NewBKTree()
+	for id, label := range []string{"bitcoin", "bitcoins", "litecoin", "bitcon", "moon", "bitcoin"} {
+		tree.Insert(label, int64(id))
+	}
+
+	cases := []struct {
+		label string
+		max   int
+		ids   []int64
+	}{
+		{"bitcon", 0, []int64{3}},
+		{"bitcon", 1, []int64{3, 0, 5}},
+		{"bitcon", 2, []int64{3, 0, 5, 1}},
+		{"bitcon", 3, []int64{3, 0, 5, 1, 2}},
+		{"xyz", 2, []int64{}},
+	}
+	for _, c := range cases {
+		ids := make([]int64, 0)
+		for _, hit := range tree.Search(c.label, c.max) {
+			ids = append(ids, hit.Id)
+		}
+		if !reflect.DeepEqual(ids, c.ids) {
+			t.Errorf("%s ~%d: ids = %v, want %v", c.label, c.max, ids, c.ids)
+		}
+	}
+
+	hits := tree.Search("bitcon", 1)
+	if hits[0].Score != 1 || math.Abs(hits[1].Score-6.0/7) > 1e-9 {
+		t.Errorf("scores = %+v", hits)
+	}
+}
+
+func TestBKTreeSearchOrder(t *testing.T) {
+	tree := search.NewBKTree()
+	for id, label := range []string{"zoon", "moon", "noon", "mon", "moo"} {
+		tree.Insert(label, int64(id))
+	}
+
+	labels := make([]string, 0)
+	for _, hit := range tree.Search("moon", 1) {
+		labels = append(labels, hit.Label)
+	}
+	want := []string{"moon", "mon", "moo", "noon", "zoon"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+}
+
+func TestFuzzyRequest(t *testing.T) {
+	req := search.Request{Mode: search.ModeFuzzy, Name: " BitCon ", MinWidth: 3}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "bitcon" || req.MaxDistance != search.DefaultFuzzyDistance {
+		t.Errorf("normalized %+v", req)
+	}
+	want := []search.Filter{{Expr: "length__gte", Args: []interface{}{3}}}
+	if q := req.Build(); !reflect.DeepEqual(q.Filters, want) {
+		t.Errorf("filters = %+v, want %+v", q.Filters, want)
+	}
+
+	for _, bad := range []search.Request{
+		{Mode: "regex", Name: "a"},
+		{Mode: search.ModeFuzzy},
+		{Mode: search.ModeFuzzy, Name: "a", MaxDistance: 4},
+		{Mode: search.ModeFuzzy, Name: "a", Cursor: "x"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: expected error", bad)
+		}
+	}
+}
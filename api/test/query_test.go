@@ -0,0 +1,296 @@
+package test
+
+import (
+	"api/search"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"utils/names"
+
+	"github.com/astaxie/beego/orm"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func init() {
+	dictionary, err := names.LoadDictionary("../../conf/words", []string{"en", "pinyin", "names", "crypto"})
+	if err != nil {
+		panic(err)
+	}
+	search.Dictionary = dictionary
+}
+
+func TestQueryFilters(t *testing.T) {
+	cases := []struct {
+		name    string
+		req     search.Request
+		filters []search.Filter
+	}{
+		{"empty", search.Request{}, nil},
+		{"name", search.Request{Name: "abc"}, []search.Filter{
+			{Expr: "content__icontains", Args: []interface{}{"abc"}},
+		}},
+		{"typeList", search.Request{TypeList: []string{"sats", "btc"}}, []search.Filter{
+			{Expr: "type__in", Args: []interface{}{[]string{"sats", "btc"}}},
+		}},
+		{"startWith", search.Request{StartWith: "a"}, []search.Filter{
+			{Expr: "content__istartswith", Args: []interface{}{"a"}},
+		}},
+		{"endWith", search.Request{EndWith: "z"}, []search.Filter{
+			{Expr: "content__iendswith", Args: []interface{}{"z"}},
+		}},
+		{"minWidth", search.Request{MinWidth: 3}, []search.Filter{
+			{Expr: "length__gte", Args: []interface{}{3}},
+		}},
+		{"maxWidth", search.Request{MaxWidth: 5}, []search.Filter{
+			{Expr: "length__lte", Args: []interface{}{5}},
+		}},
+		{"minWidth and maxWidth", search.Request{MinWidth: 3, MaxWidth: 5}, []search.Filter{
+			{Expr: "length__gte", Args: []interface{}{3}},
+			{Expr: "length__lte", Args: []interface{}{5}},
+		}},
+		{"digits", search.Request{WordsType: intPtr(search.WordsDigit)}, []search.Filter{
+			{Expr: "charset", Args: []interface{}{"digit"}},
+		}},
+		{"letters", search.Request{WordsType: intPtr(search.WordsLetter)}, []search.Filter{
+			{Expr: "charset", Args: []interface{}{"letter"}},
+		}},
+		{"emoji", search.Request{WordsType: intPtr(search.WordsEmoji)}, []search.Filter{
+			{Expr: "charset", Args: []interface{}{"emoji"}},
+		}},
+		{"charset", search.Request{Charset: "alnum"}, []search.Filter{
+			{Expr: "charset", Args: []interface{}{"alnum"}},
+		}},
+		{"category", search.Request{Category: "10K"}, []search.Filter{
+			{Expr: "id", Raw: "IN (SELECT domain_id FROM domain_category WHERE category = '10K')"},
+		}},
+		{"flags", search.Request{HasDigit: boolPtr(true), HasLetter: boolPtr(false), HasEmoji: boolPtr(false)}, []search.Filter{
+			{Expr: "has_digit", Args: []interface{}{true}},
+			{Expr: "has_letter", Args: []interface{}{false}},
+			{Expr: "has_emoji", Args: []interface{}{false}},
+		}},
+		{"palindrome", search.Request{Palindrome: boolPtr(true)}, []search.Filter{
+			{Expr: "palindrome", Args: []interface{}{true}},
+		}},
+		{"minRun", search.Request{MinRun: 3}, []search.Filter{
+			{Expr: "max_run__gte", Args: []interface{}{3}},
+		}},
+		{"pattern ABBA.sats", search.Request{Pattern: "ABBA.sats"}, []search.Filter{
+			{Expr: "type", Args: []interface{}{"sats"}},
+			{Expr: "length", Args: []interface{}{4}},
+			{Expr: "shape", Args: []interface{}{"ABBA"}},
+		}},
+		{"pattern NNN-L", search.Request{Pattern: "NNN-L"}, []search.Filter{
+			{Expr: "length", Args: []interface{}{5}},
+			{Expr: "mask", Args: []interface{}{"NNNOL"}},
+			{Expr: "content", Raw: "LIKE '___-_'"},
+		}},
+		{"pattern AAN", search.Request{Pattern: "AAN"}, []search.Filter{
+			{Expr: "length", Args: []interface{}{3}},
+			{Expr: "mask", Raw: "LIKE '__N'"},
+			{Expr: "shape__in", Args: []interface{}{[]string{"AAA", "AAB"}}},
+		}},
+		{"dictionary words", search.Request{IsWord: boolPtr(true), WordPrefix: boolPtr(true), Compound: boolPtr(false)}, []search.Filter{
+			{Expr: "is_word", Args: []interface{}{true}},
+			{Expr: "word_prefix", Args: []interface{}{true}},
+			{Expr: "compound", Args: []interface{}{false}},
+		}},
+		{"wordlist", search.Request{Wordlist: "pinyin"}, []search.Filter{
+			{Expr: "id", Raw: "IN (SELECT domain_id FROM domain_word WHERE list = 'pinyin')"},
+		}},
+		{"emoji", search.Request{EmojiClass: "zwj", SkinTone: boolPtr(true), MinEmoji: 1, MaxEmoji: 2}, []search.Filter{
+			{Expr: "emoji_class", Args: []interface{}{"zwj"}},
+			{Expr: "skin_tone", Args: []interface{}{true}},
+			{Expr: "emoji_count__gte", Args: []interface{}{1}},
+			{Expr: "emoji_count__lte", Args: []interface{}{2}},
+		}},
+		{"notLike excludes", search.Request{NotLike: "0"}, []search.Filter{
+			{Expr: "content__icontains", Args: []interface{}{"0"}, Exclude: true},
+		}},
+		{"combined", search.Request{Name: "a", TypeList: []string{"sats"}, NotLike: "b"}, []search.Filter{
+			{Expr: "content__icontains", Args: []interface{}{"a"}},
+			{Expr: "type__in", Args: []interface{}{[]string{"sats"}}},
+			{Expr: "content__icontains", Args: []interface{}{"b"}, Exclude: true},
+		}},
+	}
+
+	for _, c := range cases {
+		req := c.req
+		if err := req.Validate(); err != nil {
+			t.Fatalf("%s: unexpected error %v", c.name, err)
+		}
+		q := req.Build()
+		if !reflect.DeepEqual(q.Filters, c.filters) {
+			t.Errorf("%s: filters = %+v, want %+v", c.name, q.Filters, c.filters)
+		}
+	}
+}
+
+func TestQueryOrders(t *testing.T) {
+	cases := []struct {
+		orderType int
+		orderBy   []string
+	}{
+		{search.OrderIdDesc, []string{"-id"}},
+		{search.OrderIdAsc, []string{"id"}},
+		{search.OrderContentAsc, []string{"content", "id"}},
+		{search.OrderContentDesc, []string{"-content", "-id"}},
+		{search.OrderValueAsc, []string{"value", "id"}},
+		{search.OrderValueDesc, []string{"-value", "-id"}},
+		{search.OrderShortest, []string{"length", "id"}},
+		{search.OrderEmojiCount, []string{"emoji_count", "id"}},
+	}
+
+	for _, c := range cases {
+		for _, filter := range []search.Request{{}, {Name: "a"}, {NotLike: "a", MinWidth: 2}} {
+			req := filter
+			req.OrderType = c.orderType
+			if err := req.Validate(); err != nil {
+				t.Fatalf("orderType %d: unexpected error %v", c.orderType, err)
+			}
+			q := req.Build()
+			if !reflect.DeepEqual(q.OrderBy, c.orderBy) {
+				t.Errorf("orderType %d: orderBy = %v, want %v", c.orderType, q.OrderBy, c.orderBy)
+			}
+		}
+	}
+}
+
+func TestQueryPaging(t *testing.T) {
+	cases := []struct {
+		req    search.Request
+		limit  int
+		offset int
+	}{
+		{search.Request{}, search.DefaultPageSize, 0},
+		{search.Request{PageNum: 3, PageSize: 20}, 20, 40},
+		{search.Request{PageSize: 10000}, search.MaxPageSize, 0},
+	}
+
+	for _, c := range cases {
+		req := c.req
+		if err := req.Validate(); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		q := req.Build()
+		if q.Limit != c.limit || q.Offset != c.offset {
+			t.Errorf("%+v: limit/offset = %d/%d, want %d/%d", c.req, q.Limit, q.Offset, c.limit, c.offset)
+		}
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+	cases := []struct {
+		req search.Request
+		key string
+	}{
+		{search.Request{PageNum: -1}, search.ErrPage},
+		{search.Request{PageSize: -1}, search.ErrPage},
+		{search.Request{OrderType: 8}, search.ErrOrderType},
+		{search.Request{EmojiClass: "flag"}, search.ErrEmoji},
+		{search.Request{MinEmoji: 3, MaxEmoji: 2}, search.ErrWidth},
+		{search.Request{MinWidth: -1}, search.ErrWidth},
+		{search.Request{MinWidth: 5, MaxWidth: 3}, search.ErrWidth},
+		{search.Request{WordsType: intPtr(9)}, search.ErrWordsType},
+		{search.Request{Category: "1M"}, search.ErrCategory},
+		{search.Request{TypeList: []string{""}}, search.ErrParam},
+		{search.Request{Cursor: "not a cursor"}, search.ErrCursor},
+		{search.Request{Charset: "latin"}, search.ErrCharset},
+		{search.Request{MinRun: -1}, search.ErrParam},
+		{search.Request{Pattern: "AB?"}, search.ErrPattern},
+		{search.Request{Pattern: "AB."}, search.ErrPattern},
+		{search.Request{Wordlist: "klingon"}, search.ErrWordlist},
+	}
+
+	for _, c := range cases {
+		req := c.req
+		err := req.Validate()
+		if err == nil || err.Key != c.key {
+			t.Errorf("%+v: error = %v, want %s", c.req, err, c.key)
+		}
+	}
+}
+
+// numbers in the request body decode as float64 in a map, the typed request must accept them
+func TestQueryDecode(t *testing.T) {
+	body := `{"name":"","orderType":5,"minWidth":3,"maxWidth":4,"wordsType":0}`
+	req := search.Request{}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.OrderType != 5 || req.MinWidth != 3 || req.MaxWidth != 4 || req.WordsType == nil || *req.WordsType != 0 {
+		t.Errorf("decoded %+v", req)
+	}
+}
+
+type recordSeter struct {
+	orm.QuerySeter
+	calls []string
+}
+
+func (r *recordSeter) Filter(expr string, args ...interface{}) orm.QuerySeter {
+	r.calls = append(r.calls, "filter "+expr)
+	return r
+}
+
+func (r *recordSeter) FilterRaw(expr string, sql string) orm.QuerySeter {
+	r.calls = append(r.calls, "raw "+expr)
+	return r
+}
+
+func (r *recordSeter) Exclude(expr string, args ...interface{}) orm.QuerySeter {
+	r.calls = append(r.calls, "exclude "+expr)
+	return r
+}
+
+func TestQueryApply(t *testing.T) {
+	req := search.Request{Name: "a", MinWidth: 2, Category: "999", NotLike: "b"}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	qs := &recordSeter{}
+	req.Build().Apply(qs)
+	want := []string{"filter content__icontains", "filter length__gte", "raw id", "exclude content__icontains"}
+	if !reflect.DeepEqual(qs.calls, want) {
+		t.Errorf("calls = %v, want %v", qs.calls, want)
+	}
+}
+
+func TestQueryCursor(t *testing.T) {
+	first := search.Request{OrderType: search.OrderValueDesc}
+	if err := first.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	cursor := first.NextCursor(42, uint64(546))
+
+	next := search.Request{OrderType: search.OrderValueDesc, PageNum: 7, Cursor: cursor}
+	if err := next.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	q := next.Build()
+	want := &search.Keyset{OrderType: search.OrderValueDesc, Value: "546", Id: 42}
+	if !reflect.DeepEqual(q.After, want) {
+		t.Errorf("after = %+v, want %+v", q.After, want)
+	}
+	if q.Offset != 0 {
+		t.Errorf("offset = %d, cursor paging must ignore pageNum", q.Offset)
+	}
+
+	other := search.Request{OrderType: search.OrderContentAsc, Cursor: cursor}
+	if err := other.Validate(); err == nil || err.Key != search.ErrCursor {
+		t.Errorf("cursor of another orderType: error = %v", err)
+	}
+}
+
+func TestQueryPunycode(t *testing.T) {
+	req := search.Request{Name: "xn--6stq12a27e"}
+	if err := req.Validate(); err != nil || req.Name != "比特币" {
+		t.Errorf("request name = %q, %v", req.Name, err)
+	}
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"api/search"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	q := "len:3 suffix:sats digits -contains:0 owner:bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqu9shmp minted:>800000 sort:-value"
+	req := search.Request{Q: q}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := []search.Filter{
+		{Expr: "type__in", Args: []interface{}{[]string{"sats"}}},
+		{Expr: "length__gte", Args: []interface{}{3}},
+		{Expr: "length__lte", Args: []interface{}{3}},
+		{Expr: "charset", Args: []interface{}{"digit"}},
+		{Expr: "owner", Args: []interface{}{"bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqu9shmp"}},
+		{Expr: "height__gte", Args: []interface{}{uint64(800001)}},
+		{Expr: "content__icontains", Args: []interface{}{"0"}, Exclude: true},
+	}
+	built := req.Build()
+	if !reflect.DeepEqual(built.Filters, want) {
+		t.Errorf("filters = %+v, want %+v", built.Filters, want)
+	}
+	if !reflect.DeepEqual(built.OrderBy, []string{"-value", "-id"}) {
+		t.Errorf("orderBy = %v", built.OrderBy)
+	}
+}
+
+func TestParseQueryTerms(t *testing.T) {
+	cases := []struct {
+		q    string
+		want search.Request
+	}{
+		{"abc", search.Request{Name: "abc"}},
+		{"-abc", search.Request{NotLike: "abc"}},
+		{"len:3..5", search.Request{MinWidth: 3, MaxWidth: 5}},
+		{"len:<5", search.Request{MaxWidth: 4}},
+		{"len:>=2", search.Request{MinWidth: 2}},
+		{"minted:800000..810000", search.Request{MinHeight: 800000, MaxHeight: 810000}},
+		{"suffix:sats,btc type:ord", search.Request{TypeList: []string{"sats", "btc", "ord"}}},
+		{"starts:a ends:z", search.Request{StartWith: "a", EndWith: "z"}},
+		{"has:digit -has:emoji", search.Request{HasDigit: boolPtr(true), HasEmoji: boolPtr(false)}},
+		{"is:palindrome -is:word", search.Request{Palindrome: boolPtr(true), IsWord: boolPtr(false)}},
+		{"category:10K  charset:ALNUM", search.Request{Category: "10K", Charset: "alnum"}},
+		{"emoji:zwj", search.Request{EmojiClass: "zwj"}},
+		{"script:taproot", search.Request{ScriptType: "p2tr"}},
+		{"script:P2PKH", search.Request{ScriptType: "p2pkh"}},
+		{"sort:length", search.Request{OrderType: search.OrderShortest}},
+	}
+	for _, c := range cases {
+		got := search.Request{}
+		if err := search.ParseQuery(c.q, &got); err != nil {
+			t.Errorf("%q: unexpected error %v", c.q, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: request = %+v, want %+v", c.q, got, c.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	cases := []struct {
+		q   string
+		pos int
+	}{
+		{"foo:bar", 0},
+		{"len:3 len:x", 6},
+		{"abc sort:random", 4},
+		{"  len:", 2},
+		{"-owner:bc1p", 0},
+		{"len:5..3", 0},
+		{"has:vowel", 0},
+		{"category:1M", 0},
+		{"a b", 2},
+		{"len:3 script:p2ms", 6},
+		{"len:<1", 0},
+		{"len:<0", 0},
+		{"len:<=0", 0},
+		{"len:0", 0},
+		{"abc minted:0..0", 4},
+	}
+	for _, c := range cases {
+		req := search.Request{Q: c.q}
+		err := req.Validate()
+		if err == nil || err.Key != search.ErrQuery || err.Pos != c.pos || err.Message == "" {
+			t.Errorf("%q: error = %v, want %s at %d", c.q, err, search.ErrQuery, c.pos)
+		}
+	}
+}
+
+func TestParseQueryMerge(t *testing.T) {
+	req := search.Request{Q: "suffix:sats len:3", TypeList: []string{"btc"}, MinWidth: 2, StartWith: "a"}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.TypeList, []string{"btc", "sats"}) || req.MinWidth != 3 || req.MaxWidth != 3 || req.StartWith != "a" {
+		t.Errorf("merged request = %+v", req)
+	}
+}
+
+func TestQueryOwnerAddress(t *testing.T) {
+	req := search.Request{Q: "owner:tb1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqtdxcpw"}
+	if err := req.Validate(); err == nil || err.Key != search.ErrAddress || err.Field != "owner" {
+		t.Errorf("testnet owner on mainnet: error = %v, want %s", err, search.ErrAddress)
+	}
+}
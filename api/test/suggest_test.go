@@ -0,0 +1,122 @@
+package test
+
+import (
+	"api/search"
+	"reflect"
+	"testing"
+)
+
+func suggestNames(list []search.Suggestion) []string {
+	names := make([]string, 0, len(list))
+	for _, s := range list {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestSuggest(t *testing.T) {
+	trie := search.NewTrie()
+	for _, s := range []search.Suggestion{
+		{Name: "abcd.sats", Type: "sats", Id: 1, Length: 4},
+		{Name: "abc.sats", Type: "sats", Id: 5, Length: 3},
+		{Name: "ab.sats", Type: "sats", Id: 9, Length: 2},
+		{Name: "abe.sats", Type: "sats", Id: 3, Length: 3},
+		{Name: "abc.btc", Type: "btc", Id: 2, Length: 3},
+		{Name: "xyz.sats", Type: "sats", Id: 4, Length: 3},
+	} {
+		label := s.Name[:len(s.Name)-len(s.Type)-1]
+		trie.Insert(label, s)
+	}
+
+	cases := []struct {
+		prefix string
+		types  []string
+		n      int
+		want   []string
+	}{
+		{"ab", nil, 10, []string{"ab.sats", "abc.btc", "abe.sats", "abc.sats", "abcd.sats"}},
+		{"ab", nil, 2, []string{"ab.sats", "abc.btc"}},
+		{"abc", []string{"sats"}, 10, []string{"abc.sats", "abcd.sats"}},
+		{"ab", []string{"ord"}, 10, []string{}},
+		{"q", nil, 10, []string{}},
+	}
+
+	for _, c := range cases {
+		got := suggestNames(trie.Suggest(c.prefix, c.types, c.n))
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s %v: suggest = %v, want %v", c.prefix, c.types, got, c.want)
+		}
+	}
+
+	if trie.Size() != 6 || trie.LastId() != 9 {
+		t.Errorf("size/lastId = %d/%d", trie.Size(), trie.LastId())
+	}
+}
+
+func TestSuggestCaseDistinct(t *testing.T) {
+	trie := search.NewTrie()
+	trie.Insert("abc", search.Suggestion{Name: "abc.sats", Type: "sats", Id: 1, Length: 3})
+	trie.Insert("abc", search.Suggestion{Name: "ABC.sats", Type: "sats", Id: 3, Length: 3})
+	// 同名的再次加入替换已有的
+	trie.Insert("abc", search.Suggestion{Name: "abc.sats", Type: "sats", Id: 1, Length: 3})
+
+	got := suggestNames(trie.Suggest("ab", nil, 10))
+	if want := []string{"abc.sats", "ABC.sats"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("suggest = %v, want %v", got, want)
+	}
+	if trie.Size() != 2 {
+		t.Errorf("size = %d, want 2", trie.Size())
+	}
+}
+
+func TestTrieLookup(t *testing.T) {
+	trie := search.NewTrie()
+	trie.Insert("abc", search.Suggestion{Name: "abc.sats", Type: "sats", Id: 7, Length: 3})
+	// baseline 规则集保留纯文本名称的大小写，前缀树的键是小写
+	trie.Insert("xyz", search.Suggestion{Name: "XYZ.sats", Type: "sats", Id: 8, Length: 3})
+
+	cases := []struct {
+		label, typ string
+		id         int64
+		found      bool
+	}{
+		{"abc", "sats", 7, true},
+		{"abc", "btc", 0, false},
+		{"ab", "sats", 0, false},
+		{"abcd", "sats", 0, false},
+		{"ABC", "sats", 0, false},
+		{"XYZ", "sats", 8, true},
+		{"xyz", "sats", 0, false},
+	}
+	for _, c := range cases {
+		s, ok := trie.Lookup(c.label, c.typ)
+		if ok != c.found || (ok && s.Id != c.id) {
+			t.Errorf("%s.%s: lookup = %+v %v", c.label, c.typ, s, ok)
+		}
+	}
+
+	if trie.Loaded() {
+		t.Error("new trie reported loaded")
+	}
+	trie.SetLoaded()
+	if !trie.Loaded() {
+		t.Error("trie not loaded after SetLoaded")
+	}
+}
+
+func TestSplitSuggest(t *testing.T) {
+	cases := []struct {
+		q, prefix, typePrefix string
+		hasType               bool
+	}{
+		{"ABC", "abc", "", false},
+		{"abc.sa", "abc", "sa", true},
+		{"abc.", "abc", "", true},
+	}
+	for _, c := range cases {
+		prefix, typePrefix, hasType := search.SplitSuggest(c.q)
+		if prefix != c.prefix || typePrefix != c.typePrefix || hasType != c.hasType {
+			t.Errorf("%s: split = %s %s %v", c.q, prefix, typePrefix, hasType)
+		}
+	}
+}
@@ -3,3 +3,20 @@ package enum
 const (
 	LastInscriptionId = "lastInscriptionId"
 )
+
+// 解析接口缓存key前缀，同步器写入时清除
+const (
+	ResolveCachePrefix = "resolve:"
+	ReverseCachePrefix = "reverse:"
+)
+
+// 查询分面缓存key前缀
+const (
+	FacetCachePrefix = "facets:"
+)
+
+// 名称数据版本，同步器写入 DoMain 后递增，查询缓存以此失效
+const (
+	DomainsVersion   = "domains:version"
+	QueryCachePrefix = "query:"
+)
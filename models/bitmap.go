@@ -0,0 +1,31 @@
+package models
+
+type Bitmap struct {
+	Id             int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	Block          uint64 `orm:"description(区块高度)" form:"block" json:"block"`
+	InscriptionId  string `orm:"size(66);description(序列id)" form:"inscription_id" json:"inscription_id"`
+	InscriptionNum int64  `orm:"description(铭文序号)" form:"inscription_num" json:"inscription_num"`
+	GenesisHeight  uint64 `orm:"description(铭刻区块高度)" form:"genesis_height" json:"genesis_height"`
+	Value          uint64 `orm:"description(铭文余额)" form:"value" json:"value"`
+	Owner          string `orm:"size(90);description(所有者地址)" form:"owner" json:"owner"`
+	Ctime          int64  `orm:"description(铭刻时间)" form:"ctime" json:"ctime"`
+}
+
+func (a *Bitmap) TableName() string {
+	return BitmapTBName()
+}
+
+// 多字段索引
+func (u *Bitmap) TableIndex() [][]string {
+	return [][]string{
+		[]string{"inscription_id"},
+		[]string{"owner"},
+	}
+}
+
+// 多字段唯一键
+func (u *Bitmap) TableUnique() [][]string {
+	return [][]string{
+		[]string{"block"},
+	}
+}
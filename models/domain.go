@@ -1,5 +1,7 @@
 package models
 
+import "utils/names"
+
 type DoMain struct {
 	Id            int64  `orm:"pk;description(主键id)"  form:"id" json:"id" `
 	Name          string `orm:"size(255);description(名称)" form:"name" json:"name"`
@@ -9,8 +11,32 @@ type DoMain struct {
 	Content       string `orm:"size(255);description(内容)" form:"content" json:"content"`
 	ContentData   string `orm:"size(255);description(具体内容)" form:"content_data" json:"content_data"`
 	Type          string `orm:"size(10);description(类型)" form:"type" json:"type"`
-	Owner         string `orm:"size(62);description(所有者地址)" form:"owner" json:"owner"`
+	Owner         string `orm:"size(90);description(所有者地址)" form:"owner" json:"owner"`
+	ScriptType    string `orm:"size(16);null;description(所有者地址的脚本类型)" form:"script_type" json:"script_type"`
 	Ctime         int64  `orm:"description(铭刻时间)" form:"ctime" json:"ctime"`
+	Height        uint64 `orm:"default(0);description(铭刻区块高度)" form:"height" json:"height"`
+	Length        int    `orm:"default(0);description(字符长度(字素))" form:"length" json:"length"`
+	Charset       string `orm:"size(10);description(字符类型)" form:"charset" json:"charset"`
+	HasDigit      bool   `orm:"default(false);description(含数字)" form:"has_digit" json:"has_digit"`
+	HasLetter     bool   `orm:"default(false);description(含字母)" form:"has_letter" json:"has_letter"`
+	HasEmoji      bool   `orm:"default(false);description(含Emoji)" form:"has_emoji" json:"has_emoji"`
+	Palindrome    bool   `orm:"default(false);description(回文)" form:"palindrome" json:"palindrome"`
+	MaxRun        int    `orm:"default(0);description(最长连续相同字符)" form:"max_run" json:"max_run"`
+	Shape         string `orm:"size(255);null;description(字符模式，如ABBA)" form:"shape" json:"shape"`
+	Mask          string `orm:"size(255);null;description(字符类别，如NNNL)" form:"mask" json:"mask"`
+	Skeleton      string `orm:"size(255);null;description(易混淆字符骨架，常见字符表)" form:"skeleton" json:"skeleton"`
+	EmojiCount    int    `orm:"default(0);description(emoji字素数)" form:"emoji_count" json:"emoji_count"`
+	EmojiClass    string `orm:"size(10);null;description(emoji分类：single, sequence, zwj)" form:"emoji_class" json:"emoji_class"`
+	SkinTone      bool   `orm:"default(false);description(含肤色修饰)" form:"skin_tone" json:"skin_tone"`
+	Scripts       string `orm:"size(64);null;description(书写系统，如Cyrillic,Latin)" form:"scripts" json:"scripts"`
+	IsWord        bool   `orm:"default(false);description(词典单词)" form:"is_word" json:"is_word"`
+	WordPrefix    bool   `orm:"default(false);description(以单词开头)" form:"word_prefix" json:"word_prefix"`
+	Compound      bool   `orm:"default(false);description(两个单词组合)" form:"compound" json:"compound"`
+	// 同后缀下与之骨架相同、更早铭刻的名称，查询时填充
+	ConfusableWith string `orm:"-" json:"confusable_with,omitempty"`
+	// 名称的 Unicode 和 punycode 形式，查询时填充
+	Unicode string `orm:"-" json:"unicode"`
+	Ascii   string `orm:"-" json:"ascii"`
 }
 
 func (a *DoMain) TableName() string {
@@ -25,9 +51,60 @@ func (u *DoMain) TableIndex() [][]string {
 		[]string{"inscription_id"},
 		[]string{"type"},
 		[]string{"owner"},
+		[]string{"script_type"},
+		[]string{"height"},
+		[]string{"length"},
+		[]string{"charset", "length"},
+		[]string{"palindrome"},
+		[]string{"max_run"},
+		[]string{"length", "shape"},
+		[]string{"length", "mask"},
+		[]string{"type", "skeleton"},
+		[]string{"emoji_class", "length"},
+		[]string{"emoji_count"},
+		[]string{"is_word"},
+		[]string{"compound"},
 	}
 }
 
+// SetTraits 写入预计算的名称特征
+func (a *DoMain) SetTraits(t names.Traits) {
+	a.Length = t.Length
+	a.Charset = t.Charset
+	a.HasDigit = t.HasDigit
+	a.HasLetter = t.HasLetter
+	a.HasEmoji = t.HasEmoji
+	a.Palindrome = t.Palindrome
+	a.MaxRun = t.MaxRun
+	a.Shape = t.Shape
+	a.Mask = t.Mask
+	a.Skeleton = t.Skeleton
+	a.EmojiCount = t.EmojiCount
+	a.EmojiClass = t.EmojiClass
+	a.SkinTone = t.SkinTone
+	a.Scripts = t.Scripts
+}
+
+// SetDisplay 填充名称的 Unicode 和 punycode 形式
+func (a *DoMain) SetDisplay() {
+	a.Unicode = a.Name
+	a.Ascii = names.ToASCII(a.Name)
+}
+
+// TraitColumns 特征对应的列，用于回填时 Update
+var TraitColumns = []string{"length", "charset", "has_digit", "has_letter", "has_emoji", "palindrome", "max_run", "shape", "mask", "skeleton",
+	"emoji_count", "emoji_class", "skin_tone", "scripts"}
+
+// SetWords 写入名称的词典特征
+func (a *DoMain) SetWords(w names.WordTraits) {
+	a.IsWord = w.IsWord
+	a.WordPrefix = w.WordPrefix
+	a.Compound = w.Compound
+}
+
+// WordColumns 词典特征对应的列，用于回填时 Update
+var WordColumns = []string{"is_word", "word_prefix", "compound"}
+
 // 多字段唯一键
 func (u *DoMain) TableUnique() [][]string {
 	return [][]string{
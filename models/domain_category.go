@@ -0,0 +1,27 @@
+package models
+
+type DomainCategory struct {
+	Id       int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	DomainId int64  `orm:"description(域名id)" form:"domain_id" json:"domain_id"`
+	Name     string `orm:"size(255);description(名称)" form:"name" json:"name"`
+	Type     string `orm:"size(10);description(类型)" form:"type" json:"type"`
+	Category string `orm:"size(32);description(分类)" form:"category" json:"category"`
+}
+
+func (a *DomainCategory) TableName() string {
+	return DomainCategoryTBName()
+}
+
+// 多字段索引
+func (u *DomainCategory) TableIndex() [][]string {
+	return [][]string{
+		[]string{"category", "type"},
+	}
+}
+
+// 多字段唯一键
+func (u *DomainCategory) TableUnique() [][]string {
+	return [][]string{
+		[]string{"domain_id", "category"},
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+type DomainRecord struct {
+	Id             int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	Name           string `orm:"size(255);description(名称)" form:"name" json:"name"`
+	Key            string `orm:"size(32);description(记录类型)" form:"key" json:"key"`
+	Value          string `orm:"size(255);description(记录内容)" form:"value" json:"value"`
+	InscriptionId  string `orm:"size(66);description(更新铭文id)" form:"inscription_id" json:"inscription_id"`
+	InscriptionNum int64  `orm:"description(更新铭文序号)" form:"inscription_num" json:"inscription_num"`
+	Ctime          int64  `orm:"description(更新时间)" form:"ctime" json:"ctime"`
+}
+
+func (a *DomainRecord) TableName() string {
+	return DomainRecordTBName()
+}
+
+// 多字段索引
+func (u *DomainRecord) TableIndex() [][]string {
+	return [][]string{
+		[]string{"name"},
+	}
+}
+
+// 多字段唯一键
+func (u *DomainRecord) TableUnique() [][]string {
+	return [][]string{
+		[]string{"name", "key"},
+	}
+}
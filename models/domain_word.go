@@ -0,0 +1,27 @@
+package models
+
+type DomainWord struct {
+	Id       int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	DomainId int64  `orm:"description(域名id)" form:"domain_id" json:"domain_id"`
+	Name     string `orm:"size(255);description(名称)" form:"name" json:"name"`
+	Type     string `orm:"size(10);description(类型)" form:"type" json:"type"`
+	List     string `orm:"size(32);description(词库)" form:"list" json:"list"`
+}
+
+func (a *DomainWord) TableName() string {
+	return DomainWordTBName()
+}
+
+// 多字段索引
+func (u *DomainWord) TableIndex() [][]string {
+	return [][]string{
+		[]string{"list", "type"},
+	}
+}
+
+// 多字段唯一键
+func (u *DomainWord) TableUnique() [][]string {
+	return [][]string{
+		[]string{"domain_id", "list"},
+	}
+}
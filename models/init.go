@@ -52,6 +52,14 @@ func init() {
 func initRegister() {
 	orm.RegisterModel(
 		new(DoMain),
+		new(ProfileDiff),
+		new(Bitmap),
+		new(DomainRecord),
+		new(PrimaryName),
+		new(DomainCategory),
+		new(DomainWord),
+		new(PendingClaim),
+		new(Inscription),
 	)
 }
 
@@ -64,3 +72,35 @@ func TableName(name string) string {
 func DoMainTBName() string {
 	return TableName("domain")
 }
+
+func ProfileDiffTBName() string {
+	return TableName("profile_diff")
+}
+
+func BitmapTBName() string {
+	return TableName("bitmap")
+}
+
+func DomainRecordTBName() string {
+	return TableName("domain_record")
+}
+
+func PrimaryNameTBName() string {
+	return TableName("primary_name")
+}
+
+func DomainCategoryTBName() string {
+	return TableName("domain_category")
+}
+
+func DomainWordTBName() string {
+	return TableName("domain_word")
+}
+
+func PendingClaimTBName() string {
+	return TableName("pending_claim")
+}
+
+func InscriptionTBName() string {
+	return TableName("inscription")
+}
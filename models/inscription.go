@@ -0,0 +1,41 @@
+package models
+
+// Inscription syncer 处理过的铭文元数据，Id 为铭文序号
+type Inscription struct {
+	Id            int64  `orm:"pk;description(铭文序号)" form:"id" json:"id"`
+	InscriptionId string `orm:"size(80);description(铭文id)" form:"inscription_id" json:"inscription_id"`
+	ContentType   string `orm:"size(255);null;description(内容类型)" form:"content_type" json:"content_type"`
+	ContentLength uint64 `orm:"default(0);description(内容长度)" form:"content_length" json:"content_length"`
+	// 内容的 SHA-256，内容保存在本地内容存储时有值
+	ContentHash   string `orm:"size(64);null;description(内容哈希)" form:"content_hash" json:"content_hash,omitempty"`
+	Owner         string `orm:"size(90);null;description(所有者地址)" form:"owner" json:"owner"`
+	Value         uint64 `orm:"default(0);description(铭文余额)" form:"value" json:"value"`
+	GenesisHeight uint64 `orm:"default(0);description(铭刻区块高度)" form:"genesis_height" json:"genesis_height"`
+	GenesisFee    uint64 `orm:"default(0);description(铭刻手续费)" form:"genesis_fee" json:"genesis_fee"`
+	Parent        string `orm:"size(80);null;description(父铭文id)" form:"parent" json:"parent,omitempty"`
+	Ctime         int64  `orm:"description(铭刻时间)" form:"ctime" json:"ctime"`
+	// 解析出的协议，如 sns、bitmap、brc-721-mint，非协议铭文为空
+	Protocol string `orm:"size(32);null;description(协议)" form:"protocol" json:"protocol"`
+	// 协议解析结果的 json
+	Payload string `orm:"type(text);null;description(协议内容)" form:"payload" json:"-"`
+}
+
+func (a *Inscription) TableName() string {
+	return InscriptionTBName()
+}
+
+// 多字段索引
+func (u *Inscription) TableIndex() [][]string {
+	return [][]string{
+		[]string{"owner"},
+		[]string{"protocol"},
+		[]string{"content_hash"},
+	}
+}
+
+// 多字段唯一键
+func (u *Inscription) TableUnique() [][]string {
+	return [][]string{
+		[]string{"inscription_id"},
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+// PendingClaim 尚未稳定的名称铭刻，syncer 抓取到 ord 标记为 unstable 的名称铭文时写入，索引到该名称后删除
+type PendingClaim struct {
+	Id            int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	Name          string `orm:"size(255);description(名称)" form:"name" json:"name"`
+	InscriptionId string `orm:"size(66);description(铭文id)" form:"inscription_id" json:"inscription_id"`
+	Owner         string `orm:"size(90);description(接收地址)" form:"owner" json:"owner"`
+	Ctime         int64  `orm:"description(提交时间)" form:"ctime" json:"ctime"`
+}
+
+func (a *PendingClaim) TableName() string {
+	return PendingClaimTBName()
+}
+
+// 多字段索引
+func (u *PendingClaim) TableIndex() [][]string {
+	return [][]string{
+		[]string{"name"},
+		[]string{"inscription_id"},
+		[]string{"ctime"},
+	}
+}
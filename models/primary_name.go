@@ -0,0 +1,28 @@
+package models
+
+type PrimaryName struct {
+	Id             int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	Address        string `orm:"size(90);description(地址)" form:"address" json:"address"`
+	Name           string `orm:"size(255);description(主域名)" form:"name" json:"name"`
+	InscriptionId  string `orm:"size(66);description(设置铭文id)" form:"inscription_id" json:"inscription_id"`
+	InscriptionNum int64  `orm:"description(设置铭文序号)" form:"inscription_num" json:"inscription_num"`
+	Ctime          int64  `orm:"description(设置时间)" form:"ctime" json:"ctime"`
+}
+
+func (a *PrimaryName) TableName() string {
+	return PrimaryNameTBName()
+}
+
+// 多字段索引
+func (u *PrimaryName) TableIndex() [][]string {
+	return [][]string{
+		[]string{"name"},
+	}
+}
+
+// 多字段唯一键
+func (u *PrimaryName) TableUnique() [][]string {
+	return [][]string{
+		[]string{"address"},
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+type ProfileDiff struct {
+	Id             int64  `orm:"pk;auto;description(主键id)" form:"id" json:"id"`
+	InscriptionId  string `orm:"size(66);description(序列id)" form:"inscription_id" json:"inscription_id"`
+	InscriptionNum int64  `orm:"description(铭文序号)" form:"inscription_num" json:"inscription_num"`
+	Profile        string `orm:"size(32);description(当前规则)" form:"profile" json:"profile"`
+	Name           string `orm:"size(255);description(当前规则解析结果)" form:"name" json:"name"`
+	CompareProfile string `orm:"size(32);description(对比规则)" form:"compare_profile" json:"compare_profile"`
+	CompareName    string `orm:"size(255);description(对比规则解析结果)" form:"compare_name" json:"compare_name"`
+	Ctime          int64  `orm:"description(记录时间)" form:"ctime" json:"ctime"`
+}
+
+func (a *ProfileDiff) TableName() string {
+	return ProfileDiffTBName()
+}
+
+// 多字段索引
+func (u *ProfileDiff) TableIndex() [][]string {
+	return [][]string{
+		[]string{"inscription_id"},
+		[]string{"compare_profile"},
+	}
+}
+
+// 多字段唯一键
+func (u *ProfileDiff) TableUnique() [][]string {
+	return [][]string{
+		[]string{"inscription_id", "profile", "compare_profile"},
+	}
+}
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"flag"
 	"github.com/astaxie/beego"
 	"syncer/ord"
 )
 
 func main() {
+	backfill := flag.Bool("backfill-traits", false, "recompute name traits, categories, dictionary words and owner script types of stored names and exit")
+	flag.Parse()
+
 	syncer, err := ord.NewSyncer()
 	if err != nil {
 		beego.Error("err:", err.Error())
 		return
 	}
 
+	if *backfill {
+		if err := syncer.BackfillTraits(); err != nil {
+			beego.Error("backfill traits err:", err.Error())
+		}
+		return
+	}
+
 	syncer.Run()
 }
@@ -0,0 +1,137 @@
+package ord
+
+import (
+	"enum"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/orm"
+	"models"
+	"net/url"
+	"strings"
+	"time"
+	"utils"
+	"utils/bitcoin"
+	"utils/redis"
+)
+
+// fetchOwner reads the address currently holding an inscription from ord.
+func (s *Syncer) fetchOwner(inscriptionId string) (string, error) {
+	inscriptionURL, _ := url.JoinPath(s.baseURL, "inscription", inscriptionId)
+	resp, err := utils.HttpGetResp(inscriptionURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	owner := ""
+	ddElements := doc.Find("dl dd")
+	doc.Find("dl dt").Each(func(i int, dt *goquery.Selection) {
+		if strings.ToLower(dt.Text()) != "address" {
+			return
+		}
+		dd := ddElements.Eq(i)
+		owner = dd.Text()
+		if aTag := dd.Find("a"); aTag.Length() > 0 {
+			owner = aTag.Text()
+		}
+	})
+	if owner == "" {
+		return "", fmt.Errorf("no address for inscription %s", inscriptionId)
+	}
+	return owner, nil
+}
+
+// refreshOwner moves a name to the address currently holding its inscription.
+// Names are only seen when they are inscribed, so a transfer is noticed here.
+// It reports whether the owner changed.
+func (s *Syncer) refreshOwner(o orm.Ormer, domain *models.DoMain) (bool, error) {
+	scraped, err := s.fetchOwner(domain.InscriptionId)
+	if err != nil {
+		return false, err
+	}
+	address, err := bitcoin.Decode(scraped, s.network)
+	if err != nil {
+		return false, err
+	}
+	// owners are stored and compared in their canonical form
+	owner, _ := bitcoin.Canonical(scraped, s.network)
+	if owner == domain.Owner {
+		return false, nil
+	}
+
+	oldOwner := domain.Owner
+	domain.Owner = owner
+	domain.ScriptType = address.Type
+	if _, err := o.Update(domain, "owner", "script_type"); err != nil {
+		return false, err
+	}
+	s.invalidateResolve(domain.Name, oldOwner)
+	s.invalidateResolve(domain.Name, owner)
+	return true, nil
+}
+
+// watchPrimaryOwners periodically checks that every primary name is still
+// held by the address that selected it, and drops it once the name has been
+// transferred away.
+func (s *Syncer) watchPrimaryOwners() {
+	interval, _ := beego.AppConfig.Int64("ord::owner_check_seconds")
+	if interval <= 0 {
+		interval = 600
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkPrimaryOwners()
+		case <-s.stopC:
+			return
+		}
+	}
+}
+
+func (s *Syncer) checkPrimaryOwners() {
+	o := orm.NewOrm()
+	primaries := make([]models.PrimaryName, 0)
+	if _, err := o.QueryTable(models.PrimaryNameTBName()).All(&primaries); err != nil {
+		beego.Error("query primary names err:", err.Error())
+		return
+	}
+
+	changed := false
+	for _, primary := range primaries {
+		domain := models.DoMain{Name: primary.Name}
+		if err := o.Read(&domain, "name"); err != nil {
+			beego.Error("read primary name", primary.Name, "err:", err.Error())
+			continue
+		}
+
+		moved, err := s.refreshOwner(o, &domain)
+		if err != nil {
+			beego.Error("refresh owner of", domain.Name, "err:", err.Error())
+			continue
+		}
+		changed = changed || moved
+
+		if domain.Owner != primary.Address {
+			if _, err := o.Delete(&primary); err != nil {
+				beego.Error("delete primary name err:", err.Error())
+				continue
+			}
+			s.invalidateResolve(primary.Name, primary.Address)
+		}
+	}
+
+	if changed {
+		if _, err := redis.RedisIncr(enum.DomainsVersion); err != nil {
+			beego.Error("redis incr err:", err.Error())
+		}
+	}
+}
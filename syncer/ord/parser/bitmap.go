@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+const (
+	NameBitmap = "bitmap"
+)
+
+const (
+	BitmapSuffix = ".bitmap"
+)
+
+var (
+	_ Parser = (*BitmapParser)(nil)
+)
+
+type BitmapParser struct {
+}
+
+func (p *BitmapParser) Name() string {
+	return NameBitmap
+}
+
+// Parse returns the claimed block number. The claim must also be checked
+// against the inscription's genesis height with ValidBitmapBlock.
+func (p *BitmapParser) Parse(data []byte) (interface{}, bool, error) {
+	text := string(data)
+	if !strings.HasSuffix(text, BitmapSuffix) {
+		return nil, false, nil
+	}
+
+	label := strings.TrimSuffix(text, BitmapSuffix)
+	if label == "" {
+		return nil, false, nil
+	}
+
+	// no leading zeros, "0.bitmap" is the only label starting with 0
+	if len(label) > 1 && label[0] == '0' {
+		return nil, false, nil
+	}
+
+	for _, r := range label {
+		if r < '0' || r > '9' {
+			return nil, false, nil
+		}
+	}
+
+	block, err := strconv.ParseUint(label, 10, 64)
+	if err != nil {
+		return nil, false, err
+	}
+	return block, true, nil
+}
+
+func IsBitmap(data []byte) bool {
+	return bytes.HasSuffix(data, []byte(BitmapSuffix))
+}
+
+// ValidBitmapBlock reports whether a block can be claimed by an inscription
+// revealed at genesisHeight.
+func ValidBitmapBlock(block uint64, genesisHeight uint64) bool {
+	return block <= genesisHeight
+}
+
+// ClaimsBitmap reports whether inscriptionNum takes a block already claimed by
+// claimedNum. The first inscription claiming a block wins, so only an earlier
+// inscription replaces the claim, e.g. when blocks are indexed out of order.
+func ClaimsBitmap(claimedNum int64, inscriptionNum int64) bool {
+	return inscriptionNum < claimedNum
+}
@@ -0,0 +1,94 @@
+package parser_test
+
+import (
+	"syncer/ord/parser"
+	"testing"
+)
+
+func TestBitmapParser(t *testing.T) {
+	cases := []struct {
+		body  string
+		valid bool
+		block uint64
+	}{
+		{"0.bitmap", true, 0},
+		{"840000.bitmap", true, 840000},
+		{"00.bitmap", false, 0},
+		{"0840000.bitmap", false, 0},
+		{".bitmap", false, 0},
+		{"-1.bitmap", false, 0},
+		{"84a.bitmap", false, 0},
+		{"840 000.bitmap", false, 0},
+		{"840000.Bitmap", false, 0},
+		{"840000.bitmap\n", false, 0},
+		{"840000", false, 0},
+		{"99999999999999999999.bitmap", false, 0},
+	}
+
+	bitmapParser := parser.BitmapParser{}
+	for _, c := range cases {
+		data, valid, _ := bitmapParser.Parse([]byte(c.body))
+		if valid != c.valid {
+			t.Errorf("Parse(%q) valid = %v, want %v", c.body, valid, c.valid)
+			continue
+		}
+		if valid && data.(uint64) != c.block {
+			t.Errorf("Parse(%q) = %v, want %d", c.body, data, c.block)
+		}
+	}
+}
+
+func TestIsBitmap(t *testing.T) {
+	cases := []struct {
+		body string
+		want bool
+	}{
+		{"840000.bitmap", true},
+		{"abc.bitmap", true},
+		{"840000.sats", false},
+		{"840000.bitmap\n", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := parser.IsBitmap([]byte(c.body)); got != c.want {
+			t.Errorf("IsBitmap(%q) = %v, want %v", c.body, got, c.want)
+		}
+	}
+}
+
+func TestValidBitmapBlock(t *testing.T) {
+	cases := []struct {
+		block, genesisHeight uint64
+		want                 bool
+	}{
+		{0, 0, true},
+		{839999, 840000, true},
+		{840000, 840000, true},
+		// 不能认领铭刻时还没有出的区块
+		{840001, 840000, false},
+	}
+	for _, c := range cases {
+		if got := parser.ValidBitmapBlock(c.block, c.genesisHeight); got != c.want {
+			t.Errorf("ValidBitmapBlock(%d, %d) = %v, want %v", c.block, c.genesisHeight, got, c.want)
+		}
+	}
+}
+
+func TestClaimsBitmap(t *testing.T) {
+	cases := []struct {
+		claimedNum, inscriptionNum int64
+		want                       bool
+	}{
+		// 先认领的铭文保留区块
+		{100, 200, false},
+		// 同一铭文重复处理
+		{100, 100, false},
+		// 更早的铭文后被索引
+		{200, 100, true},
+	}
+	for _, c := range cases {
+		if got := parser.ClaimsBitmap(c.claimedNum, c.inscriptionNum); got != c.want {
+			t.Errorf("ClaimsBitmap(%d, %d) = %v, want %v", c.claimedNum, c.inscriptionNum, got, c.want)
+		}
+	}
+}
@@ -1,7 +1,5 @@
 package parser
 
-import "strings"
-
 const (
 	NameDomain = "text/plain;charset=utf-8"
 )
@@ -32,6 +30,7 @@ func (m NameDomainMint) Validate() bool {
 }
 
 type NameDomainParser struct {
+	Profile *Profile
 }
 
 func (p *NameDomainParser) Name() string {
@@ -45,15 +44,19 @@ func (p *NameDomainParser) Parse(data []byte) (interface{}, bool, error) {
 		return nil, false, err
 	}
 
-	count := strings.Count(mint.Name, ".")
-	if count != 1 {
+	if !mint.Validate() {
 		return nil, false, nil
 	}
 
-	names := strings.Split(mint.Name, ".")
-	if len(names) != 2 {
+	profile := p.Profile
+	if profile == nil {
+		profile = DefaultProfile()
+	}
+
+	name, valid := profile.JSON.Normalize(mint.Name)
+	if !valid {
 		return nil, false, nil
 	}
 
-	return strings.ToLower(mint.Name), mint.Validate(), nil
+	return name, true, nil
 }
@@ -14,6 +14,9 @@ func init() {
 	registerParser(&BRC721MintParser{})
 	registerParser(&BRC721UpdateParser{})
 	registerParser(&NameDomainParser{})
+	registerParser(&BitmapParser{})
+	registerParser(&NameDomainUpdateParser{})
+	registerParser(&NamePrimaryParser{})
 }
 
 type Parser interface {
@@ -0,0 +1,62 @@
+package parser
+
+const (
+	NameDomainPrimary = "sns-primary"
+)
+
+var (
+	_ Parser    = (*NamePrimaryParser)(nil)
+	_ Validator = (*NamePrimary)(nil)
+)
+
+type NamePrimary struct {
+	P    string `json:"p"`
+	Op   string `json:"op"`
+	Name string `json:"name"`
+}
+
+func (m NamePrimary) Validate() bool {
+	if m.P != Domain {
+		return false
+	}
+	if m.Op != "primary" {
+		return false
+	}
+	if m.Name == "" {
+		return false
+	}
+	return true
+}
+
+type NamePrimaryParser struct {
+	Profile *Profile
+}
+
+func (p *NamePrimaryParser) Name() string {
+	return NameDomainPrimary
+}
+
+// Parse accepts {"p":"sns","op":"primary","name":"alice.sats"} and returns the
+// normalized name.
+func (p *NamePrimaryParser) Parse(data []byte) (interface{}, bool, error) {
+	var primary NamePrimary
+	err := json.Unmarshal(data, &primary)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !primary.Validate() {
+		return nil, false, nil
+	}
+
+	profile := p.Profile
+	if profile == nil {
+		profile = DefaultProfile()
+	}
+
+	name, valid := profile.NormalizeName(primary.Name)
+	if !valid {
+		return nil, false, nil
+	}
+	return name, true, nil
+}
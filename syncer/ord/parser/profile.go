@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"utils/names"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Profile adds inscription parsing to the shared name rules in utils/names,
+// so the api can normalize names without depending on the syncer.
+type Profile struct {
+	*names.Profile
+}
+
+// ProfileResult is the outcome of parsing one inscription under one profile.
+type ProfileResult struct {
+	Profile string
+	Name    string
+	Format  string
+	Valid   bool
+}
+
+// DefaultProfile is used when no profile is configured. It keeps the rules
+// the indexer shipped with, so existing databases stay consistent.
+func DefaultProfile() *Profile {
+	return &Profile{names.DefaultProfile()}
+}
+
+func GetProfile(name string) (*Profile, error) {
+	profile, err := names.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{profile}, nil
+}
+
+// Parse validates an inscription body and returns the normalized name.
+func (p *Profile) Parse(contentType string, body []byte, contentLength uint64) ProfileResult {
+	res := ProfileResult{Profile: p.Name}
+	if !p.ValidContentType(contentType) {
+		return res
+	}
+
+	if contentLength == 0 {
+		contentLength = uint64(len(body))
+	}
+
+	if json.Valid(body) {
+		if !p.AllowJSON || !p.JSON.ValidLength(contentLength) {
+			return res
+		}
+		domainParser := NameDomainParser{Profile: p}
+		data, valid, err := domainParser.Parse(body)
+		if err != nil || !valid {
+			return res
+		}
+		res.Name = data.(string)
+		res.Format = FormatJSON
+		res.Valid = true
+		return res
+	}
+
+	if !p.AllowText || !p.Text.ValidLength(contentLength) {
+		return res
+	}
+
+	name, ok := p.NormalizeName(string(body))
+	if !ok {
+		return res
+	}
+	res.Name = name
+	res.Format = FormatText
+	res.Valid = true
+	return res
+}
+
+// Compare runs the same inscription through several profiles and reports
+// whether they all agree.
+func Compare(contentType string, body []byte, contentLength uint64, list ...*Profile) ([]ProfileResult, bool) {
+	results := make([]ProfileResult, 0, len(list))
+	same := true
+	for _, profile := range list {
+		res := profile.Parse(contentType, body, contentLength)
+		if len(results) > 0 {
+			first := results[0]
+			if first.Valid != res.Valid || first.Name != res.Name {
+				same = false
+			}
+		}
+		results = append(results, res)
+	}
+	return results, same
+}
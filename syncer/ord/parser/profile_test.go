@@ -0,0 +1,93 @@
+package parser_test
+
+import (
+	"strings"
+	"syncer/ord/parser"
+	"testing"
+	"utils/names"
+)
+
+func TestDefaultProfileBaseline(t *testing.T) {
+	profile := parser.DefaultProfile()
+	if profile.Name != names.ProfileBaseline {
+		t.Fatalf("default profile = %s, want %s", profile.Name, names.ProfileBaseline)
+	}
+
+	longJSON := `{"p":"sns","op":"reg","name":"abc.sats","memo":"` + strings.Repeat("x", 1100) + `"}`
+	cases := []struct {
+		contentType string
+		body        string
+		valid       bool
+		name        string
+	}{
+		{"text/plain;charset=utf-8", "abc.sats", true, "abc.sats"},
+		{"text/plain", "abc.sats", true, "abc.sats"},
+		{"text/plain", "ABC.sats", true, "ABC.sats"},
+		{"text/plain", "a\tb.sats", true, "a\tb.sats"},
+		{"text/plain", ".sats", true, ".sats"},
+		{"text/plain", "abc.", true, "abc."},
+		{"text/plain", "abc.abcdefghij", true, "abc.abcdefghij"},
+		{"application/json", "abc.sats", true, "abc.sats"},
+		{"text/plain", `{"p":"sns","op":"reg","name":"ABC.sats"}`, true, "abc.sats"},
+		{"application/json", `{"p":"sns","op":"reg","name":"a b.abcdefghijk"}`, true, "a b.abcdefghijk"},
+		{"text/plain", longJSON, true, "abc.sats"},
+		{"text/plain", "abc.abcdefghijk", false, ""},
+		{"text/plain", "a b.sats", false, ""},
+		{"text/plain", "abc.sats\n", false, ""},
+		{"text/plain", "abc", false, ""},
+		{"text/plain", "a.b.sats", false, ""},
+		{"text/plain", strings.Repeat("a", 1020) + ".sats", false, ""},
+		{"Text/Plain", "abc.sats", false, ""},
+		{"image/png", "abc.sats", false, ""},
+		{"text/plain", `{"p":"sns","op":"mint","name":"abc.sats"}`, false, ""},
+		{"text/plain", `{"p":"sns","op":"reg","name":"abc"}`, false, ""},
+	}
+	for _, c := range cases {
+		res := profile.Parse(c.contentType, []byte(c.body), 0)
+		if res.Valid != c.valid || res.Name != c.name {
+			t.Errorf("Parse(%q, %q) = %v %q, want %v %q", c.contentType, c.body, res.Valid, res.Name, c.valid, c.name)
+		}
+	}
+}
+
+func TestStrictProfile(t *testing.T) {
+	profile, err := parser.GetProfile(names.ProfileSnsStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		contentType string
+		body        string
+		valid       bool
+		name        string
+	}{
+		{"text/plain;charset=utf-8", "ABC.sats", true, "abc.sats"},
+		{"Text/Plain; charset=UTF-8", "abc.sats", true, "abc.sats"},
+		{"text/plain", "abc.sats", false, ""},
+		{"text/plain;charset=utf-8", "a\tb.sats", false, ""},
+		{"text/plain;charset=utf-8", ".sats", false, ""},
+		{"application/json", `{"p":"sns","op":"reg","name":"a b.sats"}`, false, ""},
+	}
+	for _, c := range cases {
+		res := profile.Parse(c.contentType, []byte(c.body), 0)
+		if res.Valid != c.valid || res.Name != c.name {
+			t.Errorf("Parse(%q, %q) = %v %q, want %v %q", c.contentType, c.body, res.Valid, res.Name, c.valid, c.name)
+		}
+	}
+}
+
+func TestProfilePunycode(t *testing.T) {
+	for _, name := range []string{names.ProfileBaseline, names.ProfileSnsStrict} {
+		profile, err := parser.GetProfile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, body := range []string{"xn--ls8h.sats", `{"p":"sns","op":"reg","name":"XN--LS8H.sats"}`} {
+			res := profile.Parse("text/plain;charset=utf-8", []byte(body), 0)
+			if !res.Valid || res.Name != "💩.sats" {
+				t.Errorf("%s: Parse(%q) = %v %q, want 💩.sats", name, body, res.Valid, res.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package parser
+
+const (
+	NameDomainRecord = "sns-update"
+)
+
+const (
+	RecordBtcAddress = "btc_address"
+	RecordLnAddress  = "ln_address"
+	RecordNostr      = "nostr"
+	RecordAvatar     = "avatar"
+	RecordUrl        = "url"
+)
+
+// MaxRecordValueLength matches the size of DomainRecord.Value.
+const MaxRecordValueLength = 255
+
+var (
+	_ Parser    = (*NameDomainUpdateParser)(nil)
+	_ Validator = (*NameDomainUpdate)(nil)
+)
+
+var recordKeys = map[string]bool{
+	RecordBtcAddress: true,
+	RecordLnAddress:  true,
+	RecordNostr:      true,
+	RecordAvatar:     true,
+	RecordUrl:        true,
+}
+
+// Authorized reports whether an inscription with the given parent may act on
+// the name inscribed as nameInscriptionId. Anyone can send an inscription to
+// the owner's address, only a child of the name inscription proves that its
+// holder made it.
+func Authorized(parent string, nameInscriptionId string) bool {
+	return parent != "" && parent == nameInscriptionId
+}
+
+// NameDomainUpdate attaches records to a name. Updates must be inscribed as
+// children of the name inscription, Name may be empty and otherwise has to
+// match the parent.
+type NameDomainUpdate struct {
+	P       string
+	Op      string
+	Name    string
+	Records map[string]string
+}
+
+func (m NameDomainUpdate) Validate() bool {
+	if m.P != Domain {
+		return false
+	}
+	if m.Op != "update" {
+		return false
+	}
+	if len(m.Records) == 0 {
+		return false
+	}
+	return true
+}
+
+func IsRecordKey(key string) bool {
+	return recordKeys[key]
+}
+
+type NameDomainUpdateParser struct {
+	Profile *Profile
+}
+
+func (p *NameDomainUpdateParser) Name() string {
+	return NameDomainRecord
+}
+
+// Parse accepts {"p":"sns","op":"update","name":"alice.sats","btc_address":"bc1..."}.
+// Unknown keys are ignored and an empty value removes the record.
+func (p *NameDomainUpdateParser) Parse(data []byte) (interface{}, bool, error) {
+	fields := make(map[string]interface{})
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, false, err
+	}
+
+	update := NameDomainUpdate{Records: make(map[string]string)}
+	update.P, _ = fields["p"].(string)
+	update.Op, _ = fields["op"].(string)
+	name, _ := fields["name"].(string)
+	for key, value := range fields {
+		if !IsRecordKey(key) {
+			continue
+		}
+		v, ok := value.(string)
+		if !ok || len(v) > MaxRecordValueLength {
+			return nil, false, nil
+		}
+		update.Records[key] = v
+	}
+
+	if name != "" {
+		profile := p.Profile
+		if profile == nil {
+			profile = DefaultProfile()
+		}
+		normalized, valid := profile.NormalizeName(name)
+		if !valid {
+			return nil, false, nil
+		}
+		update.Name = normalized
+	}
+
+	return &update, update.Validate(), nil
+}
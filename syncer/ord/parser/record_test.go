@@ -0,0 +1,54 @@
+package parser_test
+
+import (
+	"syncer/ord/parser"
+	"testing"
+)
+
+func TestRecordUpdateAuthorized(t *testing.T) {
+	name := "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i0"
+
+	cases := []struct {
+		desc   string
+		parent string
+		want   bool
+	}{
+		// 第三方铭刻 {"p":"sns","op":"update","name":"alice.sats",...} 并发送到持有人地址
+		{"spoofed update sent to the owner", "", false},
+		{"child of another inscription", "26482871f33f1051f450f2da9af275794c0b5f1c61ebf35e4467fb42c2813403i0", false},
+		{"child of the name inscription", name, true},
+	}
+	for _, c := range cases {
+		if got := parser.Authorized(c.parent, name); got != c.want {
+			t.Errorf("%s: Authorized(%q) = %v, want %v", c.desc, c.parent, got, c.want)
+		}
+	}
+
+	updateParser := parser.NameDomainUpdateParser{}
+	body := []byte(`{"p":"sns","op":"update","name":"alice.sats","btc_address":"bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqu9shmp"}`)
+	data, valid, err := updateParser.Parse(body)
+	if err != nil || !valid {
+		t.Fatalf("Parse(%s) = %v %v", body, valid, err)
+	}
+	if update := data.(*parser.NameDomainUpdate); update.Name != "alice.sats" {
+		t.Errorf("Name = %q, want alice.sats", update.Name)
+	}
+}
+
+func TestPrimaryNameAuthorized(t *testing.T) {
+	name := "6fb976ab49dcec017f1e201e84395983204ae1a7c2abf7ced0a85d692e442799i0"
+
+	primaryParser := parser.NamePrimaryParser{}
+	data, valid, err := primaryParser.Parse([]byte(`{"p":"sns","op":"primary","name":"alice.sats"}`))
+	if err != nil || !valid || data.(string) != "alice.sats" {
+		t.Fatalf("Parse = %v %v %v", data, valid, err)
+	}
+
+	// 发送到持有人地址的主域名铭文不能证明所有权，必须是名称铭文的子铭文
+	if parser.Authorized("", name) {
+		t.Error("primary op without parent accepted")
+	}
+	if !parser.Authorized(name, name) {
+		t.Error("primary op from a child of the name inscription rejected")
+	}
+}
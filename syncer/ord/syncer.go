@@ -1,6 +1,7 @@
 package ord
 
 import (
+	"encoding/json"
 	"enum"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
@@ -16,6 +17,9 @@ import (
 	"syscall"
 	"time"
 	"utils"
+	"utils/bitcoin"
+	"utils/blob"
+	"utils/names"
 	"utils/redis"
 )
 
@@ -44,6 +48,15 @@ type Syncer struct {
 	stopC                 chan struct{}
 	lastInscriptionIdChan chan int64
 	session               orm.Ormer
+	profile               *parser.Profile
+	compareProfile        *parser.Profile
+	dictionary            *names.Dictionary
+	network               *bitcoin.Params
+	store                 blob.Store
+	archived              *contentHashes
+	// domainsChanged is set when a DoMain row is written and cleared once the
+	// new data version has been published to the api.
+	domainsChanged bool
 }
 
 var lastInscriptionIdFile = int64(0)
@@ -53,11 +66,55 @@ func NewSyncer() (*Syncer, error) {
 	baseURL := beego.AppConfig.String("ord::addr")
 	inscription_id_start, _ := beego.AppConfig.Int64("ord::inscription_id_start")
 
+	profile := parser.DefaultProfile()
+	if name := beego.AppConfig.String("ord::profile"); name != "" {
+		p, err := parser.GetProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		profile = p
+	}
+
+	var compareProfile *parser.Profile
+	if name := beego.AppConfig.String("ord::compare_profile"); name != "" {
+		p, err := parser.GetProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		compareProfile = p
+	}
+
+	dictionary, err := loadDictionary()
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := bitcoin.Network(beego.AppConfig.String("bitcoin::network"))
+	if err != nil {
+		return nil, err
+	}
+
+	// the content archive is optional, without it bodies are parsed and dropped
+	var store blob.Store
+	if dir := beego.AppConfig.String("content::dir"); dir != "" {
+		fsStore, err := blob.NewFSStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		store = fsStore
+	}
+
 	syncer := &Syncer{Concurrency: concurrency}
 
 	syncer.session = orm.NewOrm()
 	syncer.InscriptionIdStart = inscription_id_start
 	syncer.baseURL = baseURL
+	syncer.profile = profile
+	syncer.compareProfile = compareProfile
+	syncer.dictionary = dictionary
+	syncer.network = network
+	syncer.store = store
+	syncer.archived = &contentHashes{}
 	syncer.inscriptionUidChan = make(chan string, concurrency)
 	syncer.resultChan = make(chan *result, concurrency)
 	syncer.processChan = make(chan uids)
@@ -75,11 +132,15 @@ func (s *Syncer) Run() error {
 	wg.Add(int(concurrency))
 	for i := 0; i < int(concurrency); i++ {
 		workers[i] = &Worker{
-			wid:        i,
-			baseURL:    s.baseURL,
-			uidChan:    s.inscriptionUidChan,
-			resultChan: s.resultChan,
-			stopC:      s.stopC,
+			wid:            i,
+			baseURL:        s.baseURL,
+			profile:        s.profile,
+			compareProfile: s.compareProfile,
+			store:          s.store,
+			archived:       s.archived,
+			uidChan:        s.inscriptionUidChan,
+			resultChan:     s.resultChan,
+			stopC:          s.stopC,
 		}
 
 		go func(worker *Worker) {
@@ -92,6 +153,8 @@ func (s *Syncer) Run() error {
 		s.receveResult()
 	}()
 
+	go s.watchPrimaryOwners()
+
 	go func() {
 		for {
 			lastInscriptionId, _ := s.getLastInscriptionId()
@@ -191,6 +254,9 @@ func (s *Syncer) processResults(resultsInOrder []*result, lastInscriptionId int6
 	var lastSuccessInscriptionId int64
 	for _, result := range resultsInOrder {
 		if result.inscriptionId < 0 {
+			if result.err == nil {
+				s.savePendingClaim(result.info)
+			}
 			continue
 		}
 
@@ -211,6 +277,7 @@ func (s *Syncer) processResults(resultsInOrder []*result, lastInscriptionId int6
 		lastSuccessInscriptionId = result.inscriptionId
 		count++
 	}
+	s.publishDomainsVersion()
 	if lastSuccessInscriptionId > 0 && lastSuccessInscriptionId > lastInscriptionId {
 		err := s.saveLastInscriptionId(lastSuccessInscriptionId)
 		if err != nil {
@@ -224,22 +291,179 @@ func (s *Syncer) processResults(resultsInOrder []*result, lastInscriptionId int6
 func (s *Syncer) processResult(result *result) error {
 	inscriptionId := result.inscriptionId
 	info := result.info
+
+	// the owner is scraped from ord, owners are stored and looked up by their
+	// canonical form and an owner that is not a valid address of the
+	// configured network is not stored at all
+	owner, _ := info["address"].(string)
+	address, ownerErr := bitcoin.Decode(owner, s.network)
+	if ownerErr == nil {
+		info["address"], _ = bitcoin.Canonical(owner, s.network)
+		info["script_type"] = address.Type
+	} else {
+		info["address"] = ""
+	}
+
+	if results, ok := info["profile_results"].([]parser.ProfileResult); ok {
+		s.saveProfileDiff(inscriptionId, info, results)
+	}
+	if err := s.saveInscription(inscriptionId, info); err != nil {
+		return err
+	}
+
 	content_parser, ok := info["content_parser"].(string)
 	if !ok {
 		return nil
 	}
 
+	// drop protocol inscriptions whose owner is invalid
+	if ownerErr != nil {
+		beego.Error("inscription", inscriptionId, "has invalid owner", owner, ":", ownerErr.Error())
+		return nil
+	}
+
 	switch content_parser {
 	case parser.NameDomain:
 		err := s.processDomainMint(inscriptionId, info)
 		if err != nil {
 			return err
 		}
+	case parser.NameDomainRecord:
+		err := s.processRecordUpdate(inscriptionId, info)
+		if err != nil {
+			return err
+		}
+	case parser.NameDomainPrimary:
+		err := s.processPrimaryName(inscriptionId, info)
+		if err != nil {
+			return err
+		}
+	case parser.NameBitmap:
+		err := s.processBitmapMint(inscriptionId, info)
+		if err != nil {
+			return err
+		}
 	default:
 	}
 	return nil
 }
 
+// saveInscription stores the metadata and the parsed protocol payload of
+// every processed inscription, so the api can serve it without asking ord.
+func (s *Syncer) saveInscription(inscriptionId int64, info map[string]interface{}) error {
+	inscription := models.Inscription{Id: inscriptionId}
+	err := s.session.Read(&inscription)
+	if err != nil && err != orm.ErrNoRows {
+		beego.Error("session Read inscription err:", err.Error())
+		return err
+	}
+	exists := err == nil
+
+	inscription.InscriptionId, _ = info["id"].(string)
+	inscription.ContentType, _ = info["content_type"].(string)
+	inscription.ContentLength, _ = info["content_length"].(uint64)
+	inscription.Owner, _ = info["address"].(string)
+	inscription.Value, _ = info["output_value"].(uint64)
+	inscription.GenesisHeight, _ = info["genesis_height"].(uint64)
+	inscription.GenesisFee, _ = info["genesis_fee"].(uint64)
+	inscription.Parent, _ = info["parent"].(string)
+	inscription.ContentHash, _ = info["content_hash"].(string)
+	inscription.Ctime, _ = info["timestamp"].(int64)
+	inscription.Protocol, inscription.Payload = "", ""
+	if content_parser, ok := info["content_parser"].(string); ok {
+		inscription.Protocol = protocolName(content_parser)
+		payload, err := json.Marshal(protocolPayload(content_parser, info["content"]))
+		if err == nil {
+			inscription.Payload = string(payload)
+		}
+	}
+
+	if exists {
+		_, err = s.session.Update(&inscription)
+	} else {
+		_, err = s.session.Insert(&inscription)
+	}
+	if err != nil {
+		beego.Error("session save inscription err:", err.Error())
+		return err
+	}
+	return nil
+}
+
+// protocolName is the protocol reported by the api, names are parsed by
+// content type so their parser name is not a protocol name.
+func protocolName(content_parser string) string {
+	if content_parser == parser.NameDomain {
+		return "sns"
+	}
+	return content_parser
+}
+
+func protocolPayload(content_parser string, content interface{}) interface{} {
+	switch content_parser {
+	case parser.NameDomain, parser.NameDomainPrimary:
+		return map[string]interface{}{"name": content}
+	case parser.NameBitmap:
+		return map[string]interface{}{"block": content}
+	case parser.NameDomainRecord:
+		if update, ok := content.(*parser.NameDomainUpdate); ok {
+			return map[string]interface{}{"p": update.P, "op": update.Op, "name": update.Name, "records": update.Records}
+		}
+	}
+	return content
+}
+
+// savePendingClaim records a name inscription ord still reports as unstable,
+// so the api can show the name as pending until it is indexed.
+func (s *Syncer) savePendingClaim(info map[string]interface{}) {
+	if content_parser, _ := info["content_parser"].(string); content_parser != parser.NameDomain {
+		return
+	}
+	name, _ := info["content"].(string)
+	inscription_id, _ := info["id"].(string)
+	if name == "" || inscription_id == "" {
+		return
+	}
+
+	claim := models.PendingClaim{InscriptionId: inscription_id}
+	if err := s.session.Read(&claim, "inscription_id"); err == nil {
+		return
+	} else if err != orm.ErrNoRows {
+		beego.Error("session Read pending claim err:", err.Error())
+		return
+	}
+
+	claim.Name = name
+	owner, _ := info["address"].(string)
+	claim.Owner, _ = bitcoin.Canonical(owner, s.network)
+	claim.Ctime = time.Now().Unix()
+	if _, err := s.session.Insert(&claim); err != nil {
+		beego.Error("session Insert pending claim err:", err.Error())
+	}
+}
+
+// saveProfileDiff records an inscription on which the active and the compare
+// profile disagree, so two indexer rule sets can be audited side by side.
+func (s *Syncer) saveProfileDiff(inscriptionId int64, info map[string]interface{}, results []parser.ProfileResult) {
+	if len(results) != 2 {
+		return
+	}
+
+	inscription_id, _ := info["id"].(string)
+	diff := models.ProfileDiff{
+		InscriptionId:  inscription_id,
+		InscriptionNum: inscriptionId,
+		Profile:        results[0].Profile,
+		Name:           results[0].Name,
+		CompareProfile: results[1].Profile,
+		CompareName:    results[1].Name,
+		Ctime:          time.Now().Unix(),
+	}
+	if _, err := s.session.Insert(&diff); err != nil {
+		beego.Error("session Insert profile diff err:", err.Error())
+	}
+}
+
 func (s *Syncer) processDomainMint(inscriptionId int64, info map[string]interface{}) error {
 	defer func() {
 		if err := recover(); err != nil {
@@ -264,8 +488,10 @@ func (s *Syncer) processDomainMint(inscriptionId int64, info map[string]interfac
 	}
 
 	owner := info["address"].(string)
+	script_type := info["script_type"].(string)
 	ctime := info["timestamp"].(int64)
 	content_data := info["content_data"].(string)
+	height, _ := info["genesis_height"].(uint64)
 
 	domain := models.DoMain{Name: content}
 	if err := s.session.Read(&domain, "name"); err != nil && err != orm.ErrNoRows {
@@ -280,15 +506,28 @@ func (s *Syncer) processDomainMint(inscriptionId int64, info map[string]interfac
 		domain.Content = contents[0]
 		domain.Type = content_type
 		domain.Owner = owner
+		domain.ScriptType = script_type
 		domain.Ctime = ctime
+		domain.Height = height
 		domain.Value = value
 		domain.ContentData = content_data
+		traits := names.ComputeTraits(domain.Content)
+		domain.SetTraits(traits)
+		words := s.dictionary.Match(domain.Content)
+		domain.SetWords(words)
 
 		if _, err := s.session.Insert(&domain); err != nil {
 			beego.Error("session Insert err:", err.Error())
 
 			return nil
 		}
+		s.tagCategories(&domain, traits)
+		s.tagWords(&domain, words)
+		s.domainsChanged = true
+		if _, err := s.session.QueryTable(models.PendingClaimTBName()).Filter("name", domain.Name).Delete(); err != nil {
+			beego.Error("session Delete pending claim err:", err.Error())
+		}
+		s.invalidateResolve(domain.Name, domain.Owner)
 	} else {
 		beego.Info("content:", content)
 		beego.Info("inscription_id:", inscription_id)
@@ -297,6 +536,217 @@ func (s *Syncer) processDomainMint(inscriptionId int64, info map[string]interfac
 	return nil
 }
 
+// processRecordUpdate applies record updates to a name. Updates are only
+// accepted from a child of the name inscription.
+func (s *Syncer) processRecordUpdate(inscriptionId int64, info map[string]interface{}) error {
+	defer func() {
+		if err := recover(); err != nil {
+			// 处理panic
+			return
+		}
+	}()
+
+	update := info["content"].(*parser.NameDomainUpdate)
+	inscription_id := info["id"].(string)
+	ctime := info["timestamp"].(int64)
+	parent, _ := info["parent"].(string)
+
+	domain := models.DoMain{}
+	var err error
+	if update.Name != "" {
+		domain.Name = update.Name
+		err = s.session.Read(&domain, "name")
+	} else {
+		domain.InscriptionId = parent
+		err = s.session.Read(&domain, "inscription_id")
+	}
+	if err == orm.ErrNoRows {
+		beego.Info("record update for unknown name, ignore inscription", inscriptionId)
+		return nil
+	} else if err != nil {
+		beego.Error("session Read err:", err.Error())
+		return err
+	}
+
+	if !parser.Authorized(parent, domain.InscriptionId) {
+		beego.Info("record update not a child of", domain.Name, ", ignore inscription", inscriptionId)
+		return nil
+	}
+
+	for key, value := range update.Records {
+		record := models.DomainRecord{Name: domain.Name, Key: key}
+		err := s.session.Read(&record, "name", "key")
+		if err != nil && err != orm.ErrNoRows {
+			beego.Error("session Read err:", err.Error())
+			return err
+		}
+
+		if value == "" {
+			if err == nil {
+				if _, err := s.session.Delete(&record); err != nil {
+					beego.Error("session Delete err:", err.Error())
+				}
+			}
+			continue
+		}
+
+		record.Value = value
+		record.InscriptionId = inscription_id
+		record.InscriptionNum = inscriptionId
+		record.Ctime = ctime
+		if err == orm.ErrNoRows {
+			_, err = s.session.Insert(&record)
+		} else {
+			_, err = s.session.Update(&record)
+		}
+		if err != nil {
+			beego.Error("session save record err:", err.Error())
+		}
+	}
+	s.invalidateResolve(domain.Name, domain.Owner)
+
+	return nil
+}
+
+// processPrimaryName sets a name as the primary name of the address holding
+// it. Only a child of the name inscription can select it, and the holder is
+// refreshed from ord first, since the name may have moved since it was minted.
+func (s *Syncer) processPrimaryName(inscriptionId int64, info map[string]interface{}) error {
+	defer func() {
+		if err := recover(); err != nil {
+			// 处理panic
+			return
+		}
+	}()
+
+	name := info["content"].(string)
+	inscription_id := info["id"].(string)
+	ctime := info["timestamp"].(int64)
+	parent, _ := info["parent"].(string)
+
+	domain := models.DoMain{Name: name}
+	if err := s.session.Read(&domain, "name"); err == orm.ErrNoRows {
+		beego.Info("primary name not registered, ignore inscription", inscriptionId)
+		return nil
+	} else if err != nil {
+		beego.Error("session Read err:", err.Error())
+		return err
+	}
+
+	if !parser.Authorized(parent, domain.InscriptionId) {
+		beego.Info("primary name", name, "not set by a child of its inscription, ignore inscription", inscriptionId)
+		return nil
+	}
+
+	// the primary name belongs to whoever holds the name inscription now
+	moved, err := s.refreshOwner(s.session, &domain)
+	if err != nil {
+		beego.Error("refresh owner of", name, "err:", err.Error())
+		return err
+	}
+	if moved {
+		s.domainsChanged = true
+	}
+	owner := domain.Owner
+
+	primary := models.PrimaryName{Address: owner}
+	err = s.session.Read(&primary, "address")
+	if err != nil && err != orm.ErrNoRows {
+		beego.Error("session Read err:", err.Error())
+		return err
+	}
+
+	primary.Name = name
+	primary.InscriptionId = inscription_id
+	primary.InscriptionNum = inscriptionId
+	primary.Ctime = ctime
+	if err == orm.ErrNoRows {
+		_, err = s.session.Insert(&primary)
+	} else {
+		_, err = s.session.Update(&primary)
+	}
+	if err != nil {
+		beego.Error("session save primary name err:", err.Error())
+		return nil
+	}
+	s.invalidateResolve(name, owner)
+
+	return nil
+}
+
+// invalidateResolve drops the api resolution caches touched by a name change.
+func (s *Syncer) invalidateResolve(name string, owner string) {
+	if err := redis.RedisDel(enum.ResolveCachePrefix + name).Err(); err != nil {
+		beego.Error("redis del err:", err.Error())
+	}
+	if err := redis.RedisDel(enum.ReverseCachePrefix + owner).Err(); err != nil {
+		beego.Error("redis del err:", err.Error())
+	}
+}
+
+// publishDomainsVersion bumps the DoMain data version after a batch wrote
+// names, so the api drops its cached query results and ETags.
+func (s *Syncer) publishDomainsVersion() {
+	if !s.domainsChanged {
+		return
+	}
+	if _, err := redis.RedisIncr(enum.DomainsVersion); err != nil {
+		beego.Error("redis incr err:", err.Error())
+		return
+	}
+	s.domainsChanged = false
+}
+
+// processBitmapMint stores a bitmap claim, the first inscription to claim a
+// block wins.
+func (s *Syncer) processBitmapMint(inscriptionId int64, info map[string]interface{}) error {
+	defer func() {
+		if err := recover(); err != nil {
+			// 处理panic
+			return
+		}
+	}()
+
+	block := info["content"].(uint64)
+	value := info["output_value"].(uint64)
+	inscription_id := info["id"].(string)
+	genesis_height := info["genesis_height"].(uint64)
+	owner := info["address"].(string)
+	ctime := info["timestamp"].(int64)
+
+	bitmap := models.Bitmap{Block: block}
+	err := s.session.Read(&bitmap, "block")
+	if err != nil && err != orm.ErrNoRows {
+		beego.Error("session Read err:", err.Error())
+
+		return err
+	}
+	exists := err == nil
+	if exists && !parser.ClaimsBitmap(bitmap.InscriptionNum, inscriptionId) {
+		beego.Info("bitmap already claimed:", block, bitmap.InscriptionId)
+		return nil
+	}
+
+	bitmap.InscriptionId = inscription_id
+	bitmap.InscriptionNum = inscriptionId
+	bitmap.GenesisHeight = genesis_height
+	bitmap.Value = value
+	bitmap.Owner = owner
+	bitmap.Ctime = ctime
+	if exists {
+		_, err = s.session.Update(&bitmap)
+	} else {
+		_, err = s.session.Insert(&bitmap)
+	}
+	if err != nil {
+		beego.Error("session save bitmap err:", err.Error())
+
+		return nil
+	}
+
+	return nil
+}
+
 //
 //func (s *Syncer) processBRC721Deploy(inscriptionId int64, info map[string]interface{}) error {
 //	o := info["content"].(*parser.BRC721Deploy)
@@ -428,6 +878,88 @@ func (s *Syncer) processDomainMint(inscriptionId int64, info map[string]interfac
 //	return nil
 //}
 
+// tagCategories stores the club categories a freshly indexed name belongs to.
+func (s *Syncer) tagCategories(domain *models.DoMain, traits names.Traits) {
+	for _, category := range names.Categories(domain.Content, traits) {
+		tag := models.DomainCategory{
+			DomainId: domain.Id,
+			Name:     domain.Name,
+			Type:     domain.Type,
+			Category: category,
+		}
+		if _, err := s.session.Insert(&tag); err != nil {
+			beego.Error("session Insert category err:", err.Error())
+		}
+	}
+}
+
+// tagWords stores the wordlists a freshly indexed name is a word of.
+func (s *Syncer) tagWords(domain *models.DoMain, words names.WordTraits) {
+	for _, list := range words.Lists {
+		tag := models.DomainWord{
+			DomainId: domain.Id,
+			Name:     domain.Name,
+			Type:     domain.Type,
+			List:     list,
+		}
+		if _, err := s.session.Insert(&tag); err != nil {
+			beego.Error("session Insert word err:", err.Error())
+		}
+	}
+}
+
+// loadDictionary reads the wordlists configured under [dictionary].
+func loadDictionary() (*names.Dictionary, error) {
+	dir := beego.AppConfig.DefaultString("dictionary::dir", "../conf/words")
+	lists := strings.Split(beego.AppConfig.String("dictionary::lists"), "|")
+	return names.LoadDictionary(dir, lists)
+}
+
+// BackfillTraits recomputes the name traits, categories, dictionary words and
+// owner script type of every stored DoMain.
+func (s *Syncer) BackfillTraits() error {
+	const batchSize = 1000
+	lastId := int64(-1)
+	count := 0
+	for {
+		domains := make([]models.DoMain, 0, batchSize)
+		_, err := s.session.QueryTable(models.DoMainTBName()).Filter("id__gt", lastId).OrderBy("id").Limit(batchSize).All(&domains)
+		if err != nil {
+			return err
+		}
+		if len(domains) == 0 {
+			break
+		}
+
+		for i := range domains {
+			domain := &domains[i]
+			traits := names.ComputeTraits(domain.Content)
+			domain.SetTraits(traits)
+			words := s.dictionary.Match(domain.Content)
+			domain.SetWords(words)
+			domain.ScriptType = bitcoin.ScriptType(domain.Owner, s.network)
+			columns := append(append([]string{"script_type"}, models.TraitColumns...), models.WordColumns...)
+			if _, err := s.session.Update(domain, columns...); err != nil {
+				return err
+			}
+			if _, err := s.session.QueryTable(models.DomainCategoryTBName()).Filter("domain_id", domain.Id).Delete(); err != nil {
+				return err
+			}
+			s.tagCategories(domain, traits)
+			if _, err := s.session.QueryTable(models.DomainWordTBName()).Filter("domain_id", domain.Id).Delete(); err != nil {
+				return err
+			}
+			s.tagWords(domain, words)
+			lastId = domain.Id
+			count++
+		}
+		beego.Info("backfilled traits for", count, "names")
+	}
+	s.domainsChanged = count > 0
+	s.publishDomainsVersion()
+	return nil
+}
+
 func (s *Syncer) getLastInscriptionId() (int64, error) {
 	lastInscriptionId, err := s.readLastInscriptionId()
 	if err == nil {
@@ -446,6 +978,23 @@ func (s *Syncer) readLastInscriptionId() (int64, error) {
 	return id, nil
 }
 
+// loadArchivedHashes looks up the archived content of a page of inscriptions
+// that were processed before, e.g. when the syncer is restarted or backfills.
+func (s *Syncer) loadArchivedHashes(insUids uids) {
+	hashes := make(map[string]string)
+	if len(insUids) > 0 {
+		inscriptions := make([]models.Inscription, 0, len(insUids))
+		if _, err := orm.NewOrm().QueryTable(models.InscriptionTBName()).Filter("inscription_id__in", []string(insUids)).
+			Exclude("content_hash", "").Limit(len(insUids)).All(&inscriptions, "InscriptionId", "ContentHash"); err != nil {
+			beego.Error("query archived content err:", err.Error())
+		}
+		for _, inscription := range inscriptions {
+			hashes[inscription.InscriptionId] = inscription.ContentHash
+		}
+	}
+	s.archived.set(hashes)
+}
+
 func (s *Syncer) parseInscriptions(inscriptionURL string) (string, error) {
 	if inscriptionURL == "" {
 		return "", nil
@@ -476,6 +1025,9 @@ func (s *Syncer) parseInscriptions(inscriptionURL string) (string, error) {
 		insUids = append(insUids, uid)
 	})
 
+	if s.store != nil {
+		s.loadArchivedHashes(insUids)
+	}
 	s.processChan <- insUids
 	for _, insUid := range insUids {
 		s.inscriptionUidChan <- insUid
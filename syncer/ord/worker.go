@@ -1,7 +1,6 @@
 package ord
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/astaxie/beego"
@@ -9,17 +8,23 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"syncer/ord/parser"
 	"time"
 	"utils"
+	"utils/blob"
 )
 
 type Worker struct {
-	wid        int
-	baseURL    string
-	uidChan    chan string
-	resultChan chan (*result)
-	stopC      chan struct{}
+	wid            int
+	baseURL        string
+	profile        *parser.Profile
+	compareProfile *parser.Profile
+	store          blob.Store
+	archived       *contentHashes
+	uidChan        chan string
+	resultChan     chan (*result)
+	stopC          chan struct{}
 }
 
 func (w *Worker) Start() {
@@ -119,6 +124,11 @@ func (w *Worker) parseInscriptionInfo(uid string) (map[string]interface{}, error
 			details[key] = value
 		case "address":
 			details[key] = value
+		case "parent", "parents":
+			// keep the full inscription id from the link, the text may be a thumbnail
+			if href, ok := dd.Find("a").First().Attr("href"); ok {
+				details["parent"] = strings.Replace(href, "/inscription/", "", -1)
+			}
 		default:
 			details[key] = value
 		}
@@ -132,16 +142,16 @@ func (w *Worker) parseInscriptionInfo(uid string) (map[string]interface{}, error
 }
 
 func (w *Worker) parseContent(info map[string]interface{}) error {
-	contentURL, _ := url.JoinPath(w.baseURL, "content", info["id"].(string))
-	resp, err := utils.HttpGetResp(contentURL)
+	body, err := w.fetchContent(info["id"].(string))
 	if err != nil {
 		return err
 	}
-
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if w.store != nil {
+		if hash, err := w.store.Put(body); err != nil {
+			beego.Error("store content err:", err.Error())
+		} else {
+			info["content_hash"] = hash
+		}
 	}
 
 	content_type, ok := info["content_type"].(string)
@@ -149,56 +159,40 @@ func (w *Worker) parseContent(info map[string]interface{}) error {
 		return nil
 	}
 
-	if validateContentType(content_type) {
-		if json.Valid(body) {
-			domainParser := parser.NameDomainParser{}
-			data, valid, err := domainParser.Parse(body)
-			if err != nil || !valid {
-				beego.Error("valid:", valid)
-				beego.Info("body:", string(body))
-
-				return nil
-			}
-
-			info["content_data"] = string(body)
-			info["content"] = data
-			info["content_parser"] = domainParser.Name()
-		} else {
-			count := strings.Count(string(body), ".")
-			if count != 1 {
-				return nil
-			}
-
-			names := strings.Split(string(body), ".")
-			if len(names) != 2 {
-				return nil
-			}
+	content_length, ok := info["content_length"].(uint64)
+	if !ok {
+		content_length = uint64(len(body))
+	}
 
-			if len(names[1]) > 10 {
-				return nil
-			}
+	if parser.IsBitmap(body) {
+		return w.parseBitmap(info, content_type, body)
+	}
 
-			if strings.ContainsAny(string(body), " \n") {
-				return nil
-			}
+	if w.parseRecordUpdate(info, content_type, body) {
+		return nil
+	}
 
-			content_length, ok := info["content_length"].(uint64)
-			if ok {
-				if content_length > 1024 {
-					return nil
-				}
-			} else {
-				if len(body) > 1024 {
-					return nil
-				}
-			}
+	if w.parsePrimary(info, content_type, body) {
+		return nil
+	}
 
-			info["content_data"] = string(body)
-			info["content"] = string(body)
-			info["content_parser"] = parser.NameDomain
+	if w.compareProfile != nil {
+		results, same := parser.Compare(content_type, body, content_length, w.profile, w.compareProfile)
+		if !same {
+			info["profile_results"] = results
 		}
 	}
 
+	res := w.profile.Parse(content_type, body, content_length)
+	if !res.Valid {
+		w.parseBRC721(info, body)
+		return nil
+	}
+
+	info["content_data"] = string(body)
+	info["content"] = res.Name
+	info["content_parser"] = parser.NameDomain
+
 	//var found bool
 	//for _, p := range parser.ParserList() {
 	//	data, valid, err := p.Parse(body)
@@ -220,14 +214,150 @@ func (w *Worker) parseContent(info map[string]interface{}) error {
 	return nil
 }
 
-func validateContentType(data string) bool {
-	if strings.Contains(data, "text/plain") {
-		return true
+// fetchContent reads the inscription body from the content archive when it
+// was stored before, and downloads it from ord otherwise.
+func (w *Worker) fetchContent(id string) ([]byte, error) {
+	if w.store != nil {
+		if body, ok := w.archivedContent(id); ok {
+			return body, nil
+		}
 	}
 
-	if strings.Contains(data, "application/json") {
-		return true
+	contentURL, _ := url.JoinPath(w.baseURL, "content", id)
+	resp, err := utils.HttpGetResp(contentURL)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
 
+func (w *Worker) archivedContent(id string) ([]byte, bool) {
+	hash, ok := w.archived.get(id)
+	if !ok {
+		return nil, false
+	}
+	r, err := w.store.Open(hash)
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// contentHashes maps the inscriptions of the page being crawled to the hash of
+// their archived content. The syncer fills it with one query per page, so the
+// workers don't read the database for every inscription.
+type contentHashes struct {
+	mu     sync.RWMutex
+	hashes map[string]string
+}
+
+func (h *contentHashes) set(hashes map[string]string) {
+	h.mu.Lock()
+	h.hashes = hashes
+	h.mu.Unlock()
+}
+
+func (h *contentHashes) get(id string) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hash, ok := h.hashes[id]
+	return hash, ok
+}
+
+// parseBitmap indexes "<block>.bitmap" claims with the Bitmap rules instead of
+// the name profile, bitmap claims are never treated as names.
+func (w *Worker) parseBitmap(info map[string]interface{}, content_type string, body []byte) error {
+	if !strings.Contains(content_type, "text/plain") {
+		return nil
+	}
+
+	bitmapParser := parser.BitmapParser{}
+	data, valid, err := bitmapParser.Parse(body)
+	if err != nil || !valid {
+		return nil
+	}
+
+	block := data.(uint64)
+	genesis_height, ok := info["genesis_height"].(uint64)
+	if !ok || !parser.ValidBitmapBlock(block, genesis_height) {
+		return nil
+	}
+
+	info["content_data"] = string(body)
+	info["content"] = block
+	info["content_parser"] = bitmapParser.Name()
+	return nil
+}
+
+// parseRecordUpdate recognizes {"p":"sns","op":"update",...} bodies. Only a
+// child of the name inscription can update records, the syncer checks the
+// parent against the name.
+func (w *Worker) parseRecordUpdate(info map[string]interface{}, content_type string, body []byte) bool {
+	if !w.profile.ValidContentType(content_type) {
+		return false
+	}
+
+	updateParser := parser.NameDomainUpdateParser{Profile: w.profile}
+	data, valid, err := updateParser.Parse(body)
+	if err != nil || !valid {
+		return false
+	}
+
+	update := data.(*parser.NameDomainUpdate)
+	if _, ok := info["parent"].(string); !ok {
+		return false
+	}
+
+	info["content_data"] = string(body)
+	info["content"] = update
+	info["content_parser"] = updateParser.Name()
+	return true
+}
+
+// parsePrimary recognizes {"p":"sns","op":"primary",...} bodies. Like record
+// updates it must be a child of the name inscription.
+func (w *Worker) parsePrimary(info map[string]interface{}, content_type string, body []byte) bool {
+	if !w.profile.ValidContentType(content_type) {
+		return false
+	}
+
+	primaryParser := parser.NamePrimaryParser{Profile: w.profile}
+	data, valid, err := primaryParser.Parse(body)
+	if err != nil || !valid {
+		return false
+	}
+
+	if _, ok := info["parent"].(string); !ok {
+		return false
+	}
+
+	info["content_data"] = string(body)
+	info["content"] = data
+	info["content_parser"] = primaryParser.Name()
+	return true
+}
+
+// parseBRC721 recognizes BRC-721 deploy, mint and update bodies. They are not
+// indexed yet, the payload is only stored with the inscription metadata.
+func (w *Worker) parseBRC721(info map[string]interface{}, body []byte) bool {
+	parsers := []parser.Parser{&parser.BRC721DeployParser{}, &parser.BRC721MintParser{}, &parser.BRC721UpdateParser{}}
+	for _, p := range parsers {
+		data, valid, err := p.Parse(body)
+		if err != nil || !valid {
+			continue
+		}
+		info["content"] = data
+		info["content_parser"] = p.Name()
+		return true
+	}
 	return false
 }
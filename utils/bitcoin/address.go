@@ -0,0 +1,127 @@
+package bitcoin
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrFormat   = errors.New("invalid bitcoin address")
+	ErrChecksum = errors.New("bitcoin address checksum mismatch")
+	ErrNetwork  = errors.New("bitcoin address is for another network")
+)
+
+// 地址对应的输出脚本类型
+const (
+	ScriptP2PKH  = "p2pkh"
+	ScriptP2SH   = "p2sh"
+	ScriptP2WPKH = "p2wpkh"
+	ScriptP2WSH  = "p2wsh"
+	ScriptP2TR   = "p2tr"
+	// 尚未定义的见证版本或程序长度，按 BIP350 仍是合法地址
+	ScriptWitnessUnknown = "witness_unknown"
+)
+
+// ScriptTypes 所有脚本类型，用于校验查询参数
+var ScriptTypes = []string{ScriptP2PKH, ScriptP2SH, ScriptP2WPKH, ScriptP2WSH, ScriptP2TR, ScriptWitnessUnknown}
+
+// Address 解码后的地址，Program 为公钥哈希、脚本哈希或见证程序
+type Address struct {
+	Network string
+	Type    string
+	Version byte
+	Program []byte
+}
+
+// IsTaproot 是否为 taproot(P2TR) 地址
+func (a *Address) IsTaproot() bool {
+	return a.Type == ScriptP2TR
+}
+
+// IsSegwit 是否为隔离见证地址
+func (a *Address) IsSegwit() bool {
+	return a.Type != ScriptP2PKH && a.Type != ScriptP2SH
+}
+
+// Decode 解码 base58(P2PKH/P2SH) 或 bech32/bech32m(隔离见证) 地址，并校验属于指定网络。
+// 测试网和 signet 的地址格式相同，互相无法区分
+func Decode(addr string, params *Params) (*Address, error) {
+	if addr == "" {
+		return nil, ErrFormat
+	}
+	if i := strings.LastIndexByte(addr, '1'); i > 0 && isBech32HRP(strings.ToLower(addr[:i])) {
+		hrp, version, program, err := decodeSegwit(addr)
+		if err != nil {
+			return nil, err
+		}
+		if hrp != params.Bech32HRP {
+			return nil, ErrNetwork
+		}
+		return &Address{Network: params.Name, Type: witnessType(version, program), Version: version, Program: program}, nil
+	}
+
+	version, payload, err := base58CheckDecode(addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) != 20 {
+		return nil, ErrFormat
+	}
+	switch version {
+	case params.PubKeyHashAddrID:
+		return &Address{Network: params.Name, Type: ScriptP2PKH, Program: payload}, nil
+	case params.ScriptHashAddrID:
+		return &Address{Network: params.Name, Type: ScriptP2SH, Program: payload}, nil
+	}
+	return nil, ErrNetwork
+}
+
+// ValidateAddress 校验地址属于指定网络
+func ValidateAddress(addr string, params *Params) error {
+	_, err := Decode(addr, params)
+	return err
+}
+
+// Canonical 校验地址属于指定网络并返回规范形式：bech32 不区分大小写，统一为小写，
+// 与 ord 返回的地址一致；base58 区分大小写，原样返回
+func Canonical(addr string, params *Params) (string, error) {
+	a, err := Decode(addr, params)
+	if err != nil {
+		return "", err
+	}
+	if a.IsSegwit() {
+		return strings.ToLower(addr), nil
+	}
+	return addr, nil
+}
+
+// ScriptType 地址的脚本类型，无效地址为空
+func ScriptType(addr string, params *Params) string {
+	a, err := Decode(addr, params)
+	if err != nil {
+		return ""
+	}
+	return a.Type
+}
+
+func witnessType(version byte, program []byte) string {
+	switch {
+	case version == 0 && len(program) == 20:
+		return ScriptP2WPKH
+	case version == 0 && len(program) == 32:
+		return ScriptP2WSH
+	case version == 1 && len(program) == 32:
+		return ScriptP2TR
+	}
+	return ScriptWitnessUnknown
+}
+
+// isBech32HRP 是否为已知网络的 bech32 前缀，不是则按 base58 解析
+func isBech32HRP(hrp string) bool {
+	for _, params := range networks {
+		if params.Bech32HRP == hrp {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package bitcoin_test
+
+import (
+	"testing"
+	"utils/bitcoin"
+)
+
+func TestValidateAddress(t *testing.T) {
+	mainnet, _ := bitcoin.Network(bitcoin.Mainnet)
+	testnet, _ := bitcoin.Network(bitcoin.Testnet)
+	regtest, _ := bitcoin.Network(bitcoin.Regtest)
+	cases := []struct {
+		addr   string
+		params *bitcoin.Params
+		want   error
+	}{
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", mainnet, nil},
+		{"16TL8u8PYASgF3qeQ86WL1rgV3tNgFVas", mainnet, nil},
+		{"31nUFgPZwSUpmQkGmVngvxNnq1LbyEpz1d", mainnet, nil},
+		{"bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c", mainnet, nil},
+		{"BC1QQYYQ79SAYS4NYW2QGA892HRRDFCHSLUXW64F9C", mainnet, nil},
+		{"bc1qqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqkjs7ra", mainnet, nil},
+		{"bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqu9shmp", mainnet, nil},
+		{"mfcQdBz7CZbhTMXTMy6ULFEBYUebHnuhrz", testnet, nil},
+		{"tb1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqtdxcpw", testnet, nil},
+		{"bcrt1qqyyq79says4nyw2qga892hrrdfchsluxx4hhfz", regtest, nil},
+		{"bcrt1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqx5v755", regtest, nil},
+		// 其他网络
+		{"mfcQdBz7CZbhTMXTMy6ULFEBYUebHnuhrz", mainnet, bitcoin.ErrNetwork},
+		{"tb1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqtdxcpw", mainnet, bitcoin.ErrNetwork},
+		{"bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqu9shmp", regtest, bitcoin.ErrNetwork},
+		// 校验和错误，v1 用了 bech32 而不是 bech32m
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", mainnet, bitcoin.ErrChecksum},
+		{"bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9d", mainnet, bitcoin.ErrChecksum},
+		{"bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqfeqm7r", mainnet, bitcoin.ErrChecksum},
+		// 格式错误
+		{"", mainnet, bitcoin.ErrFormat},
+		{"0x52908400098527886E0F7030069857D2E4169EE7", mainnet, bitcoin.ErrFormat},
+		{"alice.sats", mainnet, bitcoin.ErrFormat},
+		{"Bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c", mainnet, bitcoin.ErrFormat},
+	}
+	for _, c := range cases {
+		if got := bitcoin.ValidateAddress(c.addr, c.params); got != c.want {
+			t.Errorf("ValidateAddress(%q, %s) = %v, want %v", c.addr, c.params.Name, got, c.want)
+		}
+	}
+}
+
+func TestCanonicalAddress(t *testing.T) {
+	mainnet, _ := bitcoin.Network(bitcoin.Mainnet)
+	cases := []struct {
+		addr string
+		want string
+	}{
+		{"BC1QQYYQ79SAYS4NYW2QGA892HRRDFCHSLUXW64F9C", "bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c"},
+		{"bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c", "bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c"},
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+	}
+	for _, c := range cases {
+		if got, err := bitcoin.Canonical(c.addr, mainnet); err != nil || got != c.want {
+			t.Errorf("Canonical(%q) = %q %v, want %q", c.addr, got, err, c.want)
+		}
+	}
+}
+
+func TestScriptType(t *testing.T) {
+	mainnet, _ := bitcoin.Network(bitcoin.Mainnet)
+	regtest, _ := bitcoin.Network(bitcoin.Regtest)
+	cases := []struct {
+		addr   string
+		params *bitcoin.Params
+		want   string
+	}{
+		{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", mainnet, bitcoin.ScriptP2PKH},
+		{"31nUFgPZwSUpmQkGmVngvxNnq1LbyEpz1d", mainnet, bitcoin.ScriptP2SH},
+		{"bc1qqyyq79says4nyw2qga892hrrdfchsluxw64f9c", mainnet, bitcoin.ScriptP2WPKH},
+		{"bc1qqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqkjs7ra", mainnet, bitcoin.ScriptP2WSH},
+		{"bc1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqu9shmp", mainnet, bitcoin.ScriptP2TR},
+		// 64 个字符，超过原来 62 的列宽
+		{"bcrt1pqyyq79says4nyw2qga892hrrdfchslux3k2fhg4fkzmma3wv60dqx5v755", regtest, bitcoin.ScriptP2TR},
+		// v16 的 40 字节程序，76 个字符
+		{"bcrt1sqqqsyqcyq5rqwzqfpg9scrgwpugpzysnzs23v9ccrydpk8qarc0jqgfzyvjz2f38rhf4f4", regtest, bitcoin.ScriptWitnessUnknown},
+		{"mfcQdBz7CZbhTMXTMy6ULFEBYUebHnuhrz", mainnet, ""},
+		{"bc1pxyz", mainnet, ""},
+	}
+	for _, c := range cases {
+		if got := bitcoin.ScriptType(c.addr, c.params); got != c.want {
+			t.Errorf("ScriptType(%q) = %q, want %q", c.addr, got, c.want)
+		}
+	}
+}
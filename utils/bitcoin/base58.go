@@ -0,0 +1,63 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index = func() [256]int {
+	var index [256]int
+	for i := range index {
+		index[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		index[c] = i
+	}
+	return index
+}()
+
+// base58Decode 解码 base58 字符串，开头的每个 1 对应一个 0 字节
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// 按大端字节逐位乘 58 累加
+	out := make([]byte, 0, len(s))
+	for i := zeros; i < len(s); i++ {
+		carry := base58Index[s[i]]
+		if carry < 0 {
+			return nil, ErrFormat
+		}
+		for j := len(out) - 1; j >= 0; j-- {
+			carry += int(out[j]) * 58
+			out[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			out = append([]byte{byte(carry)}, out...)
+			carry >>= 8
+		}
+	}
+	return append(make([]byte, zeros), out...), nil
+}
+
+// base58CheckDecode 解码带 4 字节双 sha256 校验和的 base58 字符串，返回版本字节和负载
+func base58CheckDecode(s string) (byte, []byte, error) {
+	data, err := base58Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 5 {
+		return 0, nil, ErrFormat
+	}
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	first := sha256.Sum256(body)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], sum) {
+		return 0, nil, ErrChecksum
+	}
+	return body[0], body[1:], nil
+}
@@ -0,0 +1,125 @@
+package bitcoin
+
+import "strings"
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// 校验和常量，见 BIP173 和 BIP350
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// bech32 地址最长 90 个字符
+const maxBech32Length = 90
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// bech32Decode 解码 bech32 或 bech32m 字符串，返回前缀、去掉校验和的 5 位数据和校验和常量
+func bech32Decode(s string) (string, []byte, uint32, error) {
+	if len(s) > maxBech32Length || (strings.ToLower(s) != s && strings.ToUpper(s) != s) {
+		return "", nil, 0, ErrFormat
+	}
+	s = strings.ToLower(s)
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, 0, ErrFormat
+	}
+	hrp := s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, ErrFormat
+		}
+	}
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, 0, ErrFormat
+		}
+		data = append(data, byte(v))
+	}
+	constant := bech32Polymod(append(bech32HRPExpand(hrp), data...))
+	if constant != bech32Const && constant != bech32mConst {
+		return "", nil, 0, ErrChecksum
+	}
+	return hrp, data[:len(data)-6], constant, nil
+}
+
+// convertBits 在 5 位和 8 位分组之间转换，解码时不允许多余的填充位
+func convertBits(data []byte, from, to uint, pad bool) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to)+1)
+	for _, v := range data {
+		if uint32(v)>>from != 0 {
+			return nil, ErrFormat
+		}
+		acc = acc<<from | uint32(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(to-bits)&maxv))
+		}
+	} else if bits >= from || acc<<(to-bits)&maxv != 0 {
+		return nil, ErrFormat
+	}
+	return out, nil
+}
+
+// decodeSegwit 解码隔离见证地址，返回见证版本和见证程序。
+// 版本 0 必须使用 bech32，版本 1 及以上必须使用 bech32m
+func decodeSegwit(s string) (string, byte, []byte, error) {
+	hrp, data, constant, err := bech32Decode(s)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if len(data) < 1 || data[0] > 16 {
+		return "", 0, nil, ErrFormat
+	}
+	version := data[0]
+	program, err := convertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return "", 0, nil, ErrFormat
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return "", 0, nil, ErrFormat
+	}
+	if (version == 0) != (constant == bech32Const) {
+		return "", 0, nil, ErrChecksum
+	}
+	return hrp, version, program, nil
+}
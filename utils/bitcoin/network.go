@@ -0,0 +1,40 @@
+package bitcoin
+
+import "fmt"
+
+// 网络名称，对应配置 bitcoin::network
+const (
+	Mainnet = "mainnet"
+	Testnet = "testnet"
+	Signet  = "signet"
+	Regtest = "regtest"
+)
+
+// Params 网络的地址参数
+type Params struct {
+	Name string
+	// bech32 地址的前缀
+	Bech32HRP string
+	// base58 地址的版本字节
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+}
+
+var networks = map[string]*Params{
+	Mainnet: {Name: Mainnet, Bech32HRP: "bc", PubKeyHashAddrID: 0x00, ScriptHashAddrID: 0x05},
+	Testnet: {Name: Testnet, Bech32HRP: "tb", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4},
+	Signet:  {Name: Signet, Bech32HRP: "tb", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4},
+	Regtest: {Name: Regtest, Bech32HRP: "bcrt", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4},
+}
+
+// Network 按名称取网络参数，名称为空时为主网
+func Network(name string) (*Params, error) {
+	if name == "" {
+		name = Mainnet
+	}
+	params, ok := networks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bitcoin network %q", name)
+	}
+	return params, nil
+}
@@ -0,0 +1,40 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store 按内容的 SHA-256 寻址的存储，相同内容只保存一份
+type Store interface {
+	// Put 保存内容，返回内容的 SHA-256(十六进制)，内容已存在时不重复写入
+	Put(data []byte) (string, error)
+	// Open 读取内容，不存在时返回 ErrNotFound
+	Open(hash string) (io.ReadCloser, error)
+	// Has 内容是否已保存
+	Has(hash string) bool
+}
+
+// Hash 内容的 SHA-256(十六进制)，即内容在 Store 中的 key
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validHash 是否为 64 位小写十六进制，防止 hash 被用来拼出其他路径
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for i := 0; i < len(hash); i++ {
+		c := hash[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package blob
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// FSStore 文件系统上的 Store，内容用 zstd 压缩后保存在 <dir>/<hash[0:2]>/<hash[2:4]>/<hash>.zst
+type FSStore struct {
+	dir     string
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+var _ Store = (*FSStore)(nil)
+
+// NewFSStore 打开目录 dir 下的存储，目录不存在时创建
+func NewFSStore(dir string) (*FSStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// EncodeAll 和 DecodeAll 可以并发调用，worker 和 api 共用一个编解码器
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FSStore{dir: dir, encoder: encoder, decoder: decoder}, nil
+}
+
+func (s *FSStore) path(hash string) string {
+	return filepath.Join(s.dir, hash[0:2], hash[2:4], hash+".zst")
+}
+
+func (s *FSStore) Put(data []byte) (string, error) {
+	hash := Hash(data)
+	if s.Has(hash) {
+		return hash, nil
+	}
+
+	path := s.path(hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	// 先写临时文件再改名，读者不会看到写了一半的内容
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".tmp*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(s.encoder.EncodeAll(data, nil)); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (s *FSStore) Open(hash string) (io.ReadCloser, error) {
+	if !validHash(hash) {
+		return nil, ErrNotFound
+	}
+	compressed, err := os.ReadFile(s.path(hash))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := s.decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *FSStore) Has(hash string) bool {
+	if !validHash(hash) {
+		return false
+	}
+	_, err := os.Stat(s.path(hash))
+	return err == nil
+}
@@ -0,0 +1,74 @@
+package blob_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"utils/blob"
+)
+
+func TestFSStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := blob.NewFSStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("alice.sats "), 100)
+	hash, err := store.Put(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != blob.Hash(data) {
+		t.Errorf("hash = %s, want %s", hash, blob.Hash(data))
+	}
+	if !store.Has(hash) {
+		t.Errorf("Has(%s) = false after Put", hash)
+	}
+
+	path := filepath.Join(dir, hash[0:2], hash[2:4], hash+".zst")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() >= int64(len(data)) {
+		t.Errorf("stored %d bytes for %d bytes of content, want compressed", info.Size(), len(data))
+	}
+
+	// 相同内容只保存一份
+	again, err := store.Put(append([]byte{}, data...))
+	if err != nil || again != hash {
+		t.Errorf("Put again = %s, %v, want %s", again, err, hash)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("%d files in shard, want 1", len(entries))
+	}
+
+	r, err := store.Open(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if !bytes.Equal(got, data) {
+		t.Errorf("Open returned %d bytes, want the stored content", len(got))
+	}
+}
+
+func TestFSStoreMissing(t *testing.T) {
+	store, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hash := range []string{blob.Hash([]byte("missing")), "../../etc/passwd", ""} {
+		if store.Has(hash) {
+			t.Errorf("Has(%q) = true", hash)
+		}
+		if _, err := store.Open(hash); err != blob.ErrNotFound {
+			t.Errorf("Open(%q) err = %v, want ErrNotFound", hash, err)
+		}
+	}
+}
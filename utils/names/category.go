@@ -0,0 +1,102 @@
+package names
+
+import (
+	"time"
+)
+
+// Category 声明式的分类定义，满足全部条件的名称归入该分类
+type Category struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// 字符类型，空表示不限制
+	Charset string `json:"-"`
+	// 仅 a-z
+	Ascii bool `json:"-"`
+	// 字素长度范围，0 表示不限制
+	MinLength int `json:"-"`
+	MaxLength int `json:"-"`
+	// 回文
+	Palindrome bool `json:"-"`
+	// YYYYMMDD 格式的有效日期
+	Date bool `json:"-"`
+	// 分类的全部成员数
+	Size int64 `json:"size"`
+}
+
+var categoryList = []*Category{
+	{Name: "999", Description: "000-999", Charset: CharsetDigit, MinLength: 3, MaxLength: 3, Size: 1000},
+	{Name: "10K", Description: "0000-9999", Charset: CharsetDigit, MinLength: 4, MaxLength: 4, Size: 10000},
+	{Name: "100K", Description: "00000-99999", Charset: CharsetDigit, MinLength: 5, MaxLength: 5, Size: 100000},
+	{Name: "3L", Description: "aaa-zzz", Charset: CharsetLetter, Ascii: true, MinLength: 3, MaxLength: 3, Size: 26 * 26 * 26},
+	{Name: "palindrome", Description: "3-5 位回文数字", Charset: CharsetDigit, MinLength: 3, MaxLength: 5, Palindrome: true, Size: 100 + 100 + 1000},
+	{Name: "date", Description: "YYYYMMDD 日期(1900-2099)", Charset: CharsetDigit, MinLength: 8, MaxLength: 8, Date: true, Size: dateCount(1900, 2099)},
+}
+
+var categories = func() map[string]*Category {
+	m := make(map[string]*Category, len(categoryList))
+	for _, c := range categoryList {
+		m[c.Name] = c
+	}
+	return m
+}()
+
+func CategoryList() []*Category {
+	return categoryList
+}
+
+func GetCategory(name string) (*Category, bool) {
+	c, ok := categories[name]
+	return c, ok
+}
+
+// Match 名称标签是否属于该分类
+func (c *Category) Match(label string, t Traits) bool {
+	if c.Charset != "" && t.Charset != c.Charset {
+		return false
+	}
+	if c.MinLength > 0 && t.Length < c.MinLength {
+		return false
+	}
+	if c.MaxLength > 0 && t.Length > c.MaxLength {
+		return false
+	}
+	if c.Ascii {
+		for _, r := range label {
+			if r < 'a' || r > 'z' {
+				return false
+			}
+		}
+	}
+	if c.Palindrome && !t.Palindrome {
+		return false
+	}
+	if c.Date && !isDate(label) {
+		return false
+	}
+	return true
+}
+
+// Categories 名称标签所属的全部分类
+func Categories(label string, t Traits) []string {
+	list := make([]string, 0)
+	for _, c := range categoryList {
+		if c.Match(label, t) {
+			list = append(list, c.Name)
+		}
+	}
+	return list
+}
+
+func isDate(label string) bool {
+	d, err := time.Parse("20060102", label)
+	if err != nil {
+		return false
+	}
+	return d.Year() >= 1900 && d.Year() <= 2099
+}
+
+func dateCount(from, to int) int64 {
+	start := time.Date(from, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	return int64(end.Sub(start).Hours() / 24)
+}
@@ -0,0 +1,64 @@
+package names
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// partialConfusables 手工整理的常见易混淆字符到原型的映射，只是 Unicode TR39
+// confusables.txt 的一小部分：收录与 a-z、0-9 相似的西里尔、希腊和拉丁扩展字母，
+// 外加几组数字字母互混和多字符原型。名称已转小写，只收录小写形式。
+// 表外的易混淆字符(如数学字母、其他书写系统)不会被识别
+var partialConfusables = map[rune]string{
+	// 西里尔字母
+	'а': "a", 'в': "b", 'е': "e", 'һ': "h", 'і': "i", 'ј': "j", 'к': "k",
+	'ӏ': "l", 'м': "m", 'н': "h", 'о': "o", 'р': "p", 'с': "c", 'т': "t", 'у': "y", 'х': "x",
+	'ѕ': "s", 'ԁ': "d", 'ԛ': "q", 'ԝ': "w", 'ɡ': "g", 'ь': "b", 'п': "n", 'г': "r",
+	// 希腊字母
+	'α': "a", 'β': "b", 'ε': "e", 'η': "n", 'ι': "i", 'κ': "k", 'ν': "v", 'ο': "o", 'ρ': "p",
+	'τ': "t", 'υ': "u", 'χ': "x", 'ω': "w",
+	// 拉丁扩展
+	'ı': "i", 'ȷ': "j", 'ł': "l", 'ƚ': "l", 'ɑ': "a", 'ɩ': "i", 'ʏ': "y",
+	// 数字与字母互混
+	'0': "o", '1': "l", '|': "l",
+	// 多字符原型
+	'm': "rn", 'w': "vv",
+}
+
+// Skeleton 按 TR39 的步骤计算名称标签的骨架：NFD 分解，去掉默认可忽略字符，
+// 逐字符映射到原型，再做一次 NFD；骨架相同的名称看起来相同。
+// 映射只用 partialConfusables，不是完整的 TR39 骨架
+func Skeleton(label string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(label)) {
+		if isIgnorable(r) {
+			continue
+		}
+		if r >= 'ａ' && r <= 'ｚ' {
+			r = 'a' + (r - 'ａ')
+		} else if r >= '０' && r <= '９' {
+			r = '0' + (r - '０')
+		}
+		if p, ok := partialConfusables[r]; ok {
+			b.WriteString(p)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFD.String(b.String())
+}
+
+// isIgnorable 默认可忽略字符：零宽字符、双向控制符、软连字符、变体选择符等
+func isIgnorable(r rune) bool {
+	switch {
+	case r == 0x00ad, r == 0x034f, r == 0x061c, r == 0x115f, r == 0x1160, r == 0x3164, r == 0xfeff:
+		return true
+	case r >= 0x200b && r <= 0x200f, r >= 0x202a && r <= 0x202e, r >= 0x2060 && r <= 0x206f:
+		return true
+	case r >= 0xe0000 && r <= 0xe0fff:
+		return true
+	}
+	return unicode.Is(unicode.Variation_Selector, r)
+}
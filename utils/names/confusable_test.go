@@ -0,0 +1,29 @@
+package names_test
+
+import (
+	"testing"
+	"utils/names"
+)
+
+func TestSkeleton(t *testing.T) {
+	cases := []struct {
+		a, b string
+		same bool
+	}{
+		{"apple", "\u0430pple", true},        // Cyrillic a
+		{"paypal", "p\u0430yp\u0430l", true}, // Cyrillic a
+		{"google", "g\u043e\u043egle", true}, // Cyrillic o
+		{"alice", "al\u200bice", true},       // zero-width space
+		{"bitcoin", "b\u0456tco\u0456n", true},
+		{"modern", "rnodern", true},
+		{"l00l", "lool", true},
+		{"abc", "\uff41\uff42\uff43", true}, // fullwidth
+		{"abc", "abd", false},
+		{"caf\u00e9", "cafe", false},
+	}
+	for _, c := range cases {
+		if same := names.Skeleton(c.a) == names.Skeleton(c.b); same != c.same {
+			t.Errorf("%q/%q: same skeleton = %v, want %v", c.a, c.b, same, c.same)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package names
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 组合词中每个词的最少字符数，避免 a、i 这类单字母词让几乎所有名称都命中
+const MinWordLength = 2
+
+// Dictionary 按词库加载的单词表，每个词库对应 conf/words 下的一个 <name>.txt 文件
+type Dictionary struct {
+	names []string
+	lists map[string]map[string]bool
+}
+
+// WordTraits 名称标签的词典特征
+type WordTraits struct {
+	// 标签本身是某个词库里的词
+	IsWord bool
+	// 标签以某个词开头(含标签本身)
+	WordPrefix bool
+	// 标签由两个词拼成
+	Compound bool
+	// 标签所在的词库
+	Lists []string
+}
+
+// LoadDictionary 读取 dir 下的词库文件：每行一个词，忽略空行和 # 开头的注释
+func LoadDictionary(dir string, lists []string) (*Dictionary, error) {
+	d := &Dictionary{lists: make(map[string]map[string]bool, len(lists))}
+	for _, name := range lists {
+		name = strings.TrimSpace(name)
+		if name == "" || d.lists[name] != nil {
+			continue
+		}
+		words, err := readWords(filepath.Join(dir, name+".txt"))
+		if err != nil {
+			return nil, err
+		}
+		d.names = append(d.names, name)
+		d.lists[name] = words
+	}
+	return d, nil
+}
+
+func readWords(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words[word] = true
+	}
+	return words, scanner.Err()
+}
+
+// Lists 已加载的词库名，按配置顺序
+func (d *Dictionary) Lists() []string {
+	return d.names
+}
+
+// Has 是否加载了该词库
+func (d *Dictionary) Has(list string) bool {
+	_, ok := d.lists[list]
+	return ok
+}
+
+// Count 词库中的单词数
+func (d *Dictionary) Count(list string) int {
+	return len(d.lists[list])
+}
+
+// Lookup 包含该词的全部词库
+func (d *Dictionary) Lookup(word string) []string {
+	found := make([]string, 0)
+	for _, name := range d.names {
+		if d.lists[name][word] {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+func (d *Dictionary) isWord(word string) bool {
+	for _, words := range d.lists {
+		if words[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// Match 计算名称标签(不含后缀)的词典特征，按字素切分，标签需已转为小写
+func (d *Dictionary) Match(label string) WordTraits {
+	t := WordTraits{Lists: d.Lookup(label)}
+	t.IsWord = len(t.Lists) > 0
+
+	clusters := Graphemes(label)
+	for i := MinWordLength; i <= len(clusters); i++ {
+		if !d.isWord(strings.Join(clusters[:i], "")) {
+			continue
+		}
+		t.WordPrefix = true
+		if len(clusters)-i >= MinWordLength && d.isWord(strings.Join(clusters[i:], "")) {
+			t.Compound = true
+			break
+		}
+	}
+	return t
+}
@@ -0,0 +1,33 @@
+package names_test
+
+import (
+	"reflect"
+	"testing"
+	"utils/names"
+)
+
+func TestDictionaryMatch(t *testing.T) {
+	dictionary, err := names.LoadDictionary("../../conf/words", []string{"en", "pinyin", "names", "crypto"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		label string
+		want  names.WordTraits
+	}{
+		{"moon", names.WordTraits{IsWord: true, WordPrefix: true, Lists: []string{"en", "crypto"}}},
+		{"xiaoming", names.WordTraits{WordPrefix: true, Compound: true, Lists: []string{}}},
+		{"goldfish", names.WordTraits{WordPrefix: true, Compound: true, Lists: []string{}}},
+		{"moonxyz", names.WordTraits{WordPrefix: true, Lists: []string{}}},
+		{"xyz", names.WordTraits{Lists: []string{}}},
+		{"1234", names.WordTraits{Lists: []string{}}},
+	}
+
+	for _, c := range cases {
+		got := dictionary.Match(c.label)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: traits = %+v, want %+v", c.label, got, c.want)
+		}
+	}
+}
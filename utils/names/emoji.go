@@ -0,0 +1,95 @@
+package names
+
+import (
+	"strings"
+	"unicode"
+)
+
+// emojiTable 近似 Extended_Pictographic 与区旗字母
+var emojiTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00a9, 0x00a9, 1},
+		{0x00ae, 0x00ae, 1},
+		{0x203c, 0x203c, 1},
+		{0x2049, 0x2049, 1},
+		{0x2122, 0x2122, 1},
+		{0x2139, 0x2139, 1},
+		{0x2194, 0x21aa, 1},
+		{0x231a, 0x23ff, 1},
+		{0x24c2, 0x24c2, 1},
+		{0x25aa, 0x25fe, 1},
+		{0x2600, 0x27bf, 1},
+		{0x2934, 0x2935, 1},
+		{0x2b05, 0x2b55, 1},
+		{0x3030, 0x3030, 1},
+		{0x303d, 0x303d, 1},
+		{0x3297, 0x3299, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f000, 0x1f0ff, 1},
+		{0x1f10d, 0x1f1ff, 1},
+		{0x1f200, 0x1faff, 1},
+	},
+}
+
+// IsEmoji 字素是否为 emoji(以首个码点判断，含 ZWJ 序列和肤色修饰，以及 1️⃣ 这类键帽)
+func IsEmoji(cluster string) bool {
+	for i, r := range cluster {
+		if i == 0 && unicode.Is(emojiTable, r) {
+			return true
+		}
+		if r == 0x20e3 {
+			return true
+		}
+	}
+	return false
+}
+
+// emoji 名称的分类，仅对只含 emoji 的名称计算
+const (
+	// 单个 emoji，如 🟧
+	EmojiSingle = "single"
+	// 多个 emoji 排列，如 🍎🍊
+	EmojiSequence = "sequence"
+	// 含 ZWJ 组合序列，如 👨‍👩‍👧
+	EmojiZWJ = "zwj"
+)
+
+const (
+	zeroWidthJoiner = 0x200d
+	skinToneFirst   = 0x1f3fb
+	skinToneLast    = 0x1f3ff
+)
+
+// EmojiClass emoji 名称的分类，不是纯 emoji 时为空
+func EmojiClass(clusters []string) string {
+	if len(clusters) == 0 {
+		return ""
+	}
+	zwj := false
+	for _, cluster := range clusters {
+		if !IsEmoji(cluster) {
+			return ""
+		}
+		if strings.ContainsRune(cluster, zeroWidthJoiner) {
+			zwj = true
+		}
+	}
+	switch {
+	case zwj:
+		return EmojiZWJ
+	case len(clusters) == 1:
+		return EmojiSingle
+	}
+	return EmojiSequence
+}
+
+// HasSkinTone 字素是否带肤色修饰(U+1F3FB-1F3FF)
+func HasSkinTone(cluster string) bool {
+	for _, r := range cluster {
+		if r >= skinToneFirst && r <= skinToneLast {
+			return true
+		}
+	}
+	return false
+}
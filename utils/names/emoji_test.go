@@ -0,0 +1,32 @@
+package names_test
+
+import (
+	"testing"
+	"utils/names"
+)
+
+func TestEmojiTraits(t *testing.T) {
+	cases := []struct {
+		label    string
+		length   int
+		count    int
+		class    string
+		skinTone bool
+	}{
+		{"\U0001f7e7", 1, 1, names.EmojiSingle, false},
+		{"\U0001f34e\U0001f34a", 2, 2, names.EmojiSequence, false},
+		{"\U0001f468\u200d\U0001f469\u200d\U0001f467", 1, 1, names.EmojiZWJ, false},
+		{"\U0001f44d\U0001f3fd", 1, 1, names.EmojiSingle, true},
+		{"\U0001f1ef\U0001f1f5", 1, 1, names.EmojiSingle, false},
+		{"1\ufe0f\u20e3", 1, 1, names.EmojiSingle, false},
+		{"a\U0001f7e7", 2, 1, "", false},
+		{"abc", 3, 0, "", false},
+	}
+	for _, c := range cases {
+		tr := names.ComputeTraits(c.label)
+		if tr.Length != c.length || tr.EmojiCount != c.count || tr.EmojiClass != c.class || tr.SkinTone != c.skinTone {
+			t.Errorf("%q: length/count/class/skinTone = %d/%d/%q/%v, want %d/%d/%q/%v", c.label,
+				tr.Length, tr.EmojiCount, tr.EmojiClass, tr.SkinTone, c.length, c.count, c.class, c.skinTone)
+		}
+	}
+}
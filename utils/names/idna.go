@@ -0,0 +1,67 @@
+package names
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// ACE 前缀，punycode 编码的标签以此开头
+const acePrefix = "xn--"
+
+// ToASCII 把名称的每个标签转为 punycode，纯 ASCII 的标签保持不变。
+// 名称允许 emoji 等 IDNA2008 不允许的字符，这里只做编码，不做 IDNA 校验
+func ToASCII(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if ascii, err := idna.Punycode.ToASCII(part); err == nil {
+			parts[i] = ascii
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ToUnicode 把名称中 xn-- 开头的标签解码为 Unicode，无法解码的标签保持不变
+func ToUnicode(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if !strings.HasPrefix(strings.ToLower(part), acePrefix) {
+			continue
+		}
+		if u, err := idna.Punycode.ToUnicode(strings.ToLower(part)); err == nil {
+			parts[i] = u
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// Scripts 名称标签用到的书写系统，如 Latin、Cyrillic、Han，按名称排序；
+// 数字、标点和 emoji 属于 Common，不计入
+func Scripts(label string) []string {
+	seen := make(map[string]bool)
+	for _, r := range label {
+		if r <= unicode.MaxASCII {
+			if unicode.IsLetter(r) {
+				seen["Latin"] = true
+			}
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if name == "Common" || name == "Inherited" {
+				continue
+			}
+			if unicode.Is(table, r) {
+				seen[name] = true
+				break
+			}
+		}
+	}
+	list := make([]string, 0, len(seen))
+	for name := range seen {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,49 @@
+package names_test
+
+import (
+	"reflect"
+	"testing"
+	"utils/names"
+)
+
+func TestPunycode(t *testing.T) {
+	cases := []struct {
+		unicode, ascii string
+	}{
+		{"alice.sats", "alice.sats"},
+		{"比特币.sats", "xn--6stq12a27e.sats"},
+		{"\U0001f7e7.sats", "xn--bh9h.sats"},
+	}
+	for _, c := range cases {
+		if got := names.ToASCII(c.unicode); got != c.ascii {
+			t.Errorf("ToASCII(%q) = %q, want %q", c.unicode, got, c.ascii)
+		}
+		if got := names.ToUnicode(c.ascii); got != c.unicode {
+			t.Errorf("ToUnicode(%q) = %q, want %q", c.ascii, got, c.unicode)
+		}
+	}
+	if got := names.ToUnicode("XN--BH9H.sats"); got != "\U0001f7e7.sats" {
+		t.Errorf("upper case ace prefix: %q", got)
+	}
+	if got := names.ToUnicode("xn--!!.sats"); got != "xn--!!.sats" {
+		t.Errorf("invalid punycode must stay as is: %q", got)
+	}
+}
+
+func TestScripts(t *testing.T) {
+	cases := []struct {
+		label   string
+		scripts []string
+	}{
+		{"alice", []string{"Latin"}},
+		{"123", []string{}},
+		{"pаypal", []string{"Cyrillic", "Latin"}},
+		{"比特币", []string{"Han"}},
+		{"\U0001f7e7", []string{}},
+	}
+	for _, c := range cases {
+		if got := names.Scripts(c.label); !reflect.DeepEqual(got, c.scripts) {
+			t.Errorf("%q: scripts = %v, want %v", c.label, got, c.scripts)
+		}
+	}
+}
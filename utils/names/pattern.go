@@ -0,0 +1,216 @@
+package names
+
+import (
+	"errors"
+	"strings"
+)
+
+// 字符类别掩码
+const (
+	MaskDigit  = 'N'
+	MaskLetter = 'L'
+	MaskEmoji  = 'E'
+	MaskOther  = 'O'
+)
+
+const (
+	maxPatternLength = 16
+	// 混合模式中自由位置的上限，超过后可能的 shape 过多
+	maxFreePositions = 6
+)
+
+var ErrPattern = errors.New("invalid pattern")
+
+// Pattern 编译后的模式：按长度、类别掩码、shape 和内容匹配
+type Pattern struct {
+	Length int
+	// 类别掩码，'_' 表示任意类别
+	Mask string
+	// 可能的 shape 签名，空表示不限制
+	Shapes []string
+	// content 的 LIKE 模式，空表示没有字面字符
+	Like string
+}
+
+// Shape 按首次出现顺序给每个字素编号：1221 -> ABBA, aabb -> AABB
+func Shape(clusters []string) string {
+	seen := make(map[string]rune, len(clusters))
+	var b strings.Builder
+	for _, cluster := range clusters {
+		r, ok := seen[cluster]
+		if !ok {
+			r = rune('A' + len(seen))
+			seen[cluster] = r
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Mask 每个字素的类别：N 数字，L 字母，E emoji，O 其他
+func Mask(clusters []string) string {
+	var b strings.Builder
+	for _, cluster := range clusters {
+		b.WriteRune(maskOf(cluster))
+	}
+	return b.String()
+}
+
+func maskOf(cluster string) rune {
+	switch {
+	case isDigit(cluster):
+		return MaskDigit
+	case isLetter(cluster):
+		return MaskLetter
+	case IsEmoji(cluster):
+		return MaskEmoji
+	}
+	return MaskOther
+}
+
+// CompilePattern 编译模式串。
+// 大写字母(N、L 除外)是变量，相同字母表示相同字符，不同字母表示不同字符；
+// N 表示任意数字，L 表示任意字母；a-z、0-9、- 和 _ 按字面匹配。
+// 例如 AABB、ABBA、ABAB、ABC、NNN-L、AAAA。
+func CompilePattern(pattern string) (*Pattern, error) {
+	if pattern == "" || len(pattern) > maxPatternLength {
+		return nil, ErrPattern
+	}
+
+	// 每个位置所属的分组，变量和字面字符为具名分组，N/L 为自由位置
+	groups := make([]string, len(pattern))
+	mask := make([]byte, len(pattern))
+	like := make([]string, len(pattern))
+	hasVar, hasLiteral := false, false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		like[i] = "_"
+		switch {
+		case c == 'N':
+			mask[i] = MaskDigit
+		case c == 'L':
+			mask[i] = MaskLetter
+		case c >= 'A' && c <= 'Z':
+			mask[i] = '_'
+			groups[i] = "v" + string(c)
+			hasVar = true
+		case c >= 'a' && c <= 'z':
+			mask[i] = MaskLetter
+			groups[i] = "l" + string(c)
+			like[i] = string(c)
+			hasLiteral = true
+		case c >= '0' && c <= '9':
+			mask[i] = MaskDigit
+			groups[i] = "l" + string(c)
+			like[i] = string(c)
+			hasLiteral = true
+		case c == '-':
+			mask[i] = MaskOther
+			groups[i] = "l" + string(c)
+			like[i] = string(c)
+			hasLiteral = true
+		case c == '_':
+			mask[i] = MaskOther
+			groups[i] = "l" + string(c)
+			like[i] = "\\_"
+			hasLiteral = true
+		default:
+			return nil, ErrPattern
+		}
+	}
+
+	p := &Pattern{Length: len(pattern), Mask: string(mask)}
+	if hasLiteral {
+		p.Like = strings.Join(like, "")
+	}
+	if hasVar {
+		shapes, err := enumerateShapes(groups)
+		if err != nil {
+			return nil, err
+		}
+		p.Shapes = shapes
+	}
+	return p, nil
+}
+
+// enumerateShapes 列出与分组约束一致的全部 shape：
+// 同一具名分组必须相同，不同具名分组必须不同，自由位置可以等于任一已有字符或是新字符
+func enumerateShapes(groups []string) ([]string, error) {
+	free := 0
+	for _, g := range groups {
+		if g == "" {
+			free++
+		}
+	}
+	if free > maxFreePositions {
+		return nil, ErrPattern
+	}
+
+	shapes := make([]string, 0)
+	var walk func(i int, assigned map[string]int, named map[int]bool, symbols []int, count int)
+	walk = func(i int, assigned map[string]int, named map[int]bool, symbols []int, count int) {
+		if i == len(groups) {
+			shapes = append(shapes, shapeOf(symbols))
+			return
+		}
+
+		g := groups[i]
+		if g != "" {
+			if s, ok := assigned[g]; ok {
+				walk(i+1, assigned, named, append(symbols, s), count)
+				return
+			}
+			// 新的具名分组：可以是新字符，或是某个自由位置已用过的字符
+			for s := 0; s <= count; s++ {
+				if named[s] {
+					continue
+				}
+				assigned[g] = s
+				named[s] = true
+				next := count
+				if s == count {
+					next++
+				}
+				walk(i+1, assigned, named, append(symbols, s), next)
+				delete(assigned, g)
+				delete(named, s)
+			}
+			return
+		}
+
+		for s := 0; s <= count; s++ {
+			next := count
+			if s == count {
+				next++
+			}
+			walk(i+1, assigned, named, append(symbols, s), next)
+		}
+	}
+	walk(0, make(map[string]int), make(map[int]bool), make([]int, 0, len(groups)), 0)
+
+	// 去重
+	seen := make(map[string]bool, len(shapes))
+	list := make([]string, 0, len(shapes))
+	for _, shape := range shapes {
+		if !seen[shape] {
+			seen[shape] = true
+			list = append(list, shape)
+		}
+	}
+	return list, nil
+}
+
+// shapeOf 把符号编号按首次出现重新编号成 shape
+func shapeOf(symbols []int) string {
+	seen := make(map[int]rune, len(symbols))
+	var b strings.Builder
+	for _, s := range symbols {
+		r, ok := seen[s]
+		if !ok {
+			r = rune('A' + len(seen))
+			seen[s] = r
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
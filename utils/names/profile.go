@@ -0,0 +1,197 @@
+package names
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 名称规则集，syncer 按配置的规则集建索引，api 按同一规则集规范化用户输入
+const (
+	ProfileBaseline         = "baseline"
+	ProfileSnsStrict        = "sns-strict"
+	ProfileUnisatCompatible = "unisat-compatible"
+)
+
+var (
+	profileLock sync.Mutex
+	profiles    = make(map[string]*Profile)
+)
+
+func init() {
+	registerProfile(&Profile{
+		Name:      ProfileBaseline,
+		AllowJSON: true,
+		AllowText: true,
+		JSON: NameRules{
+			FoldCase: true,
+		},
+		Text: NameRules{
+			KeepCase:         true,
+			RejectChars:      " \n",
+			MaxSuffixLength:  10,
+			MaxContentLength: 1024,
+		},
+	})
+	strict := NameRules{
+		FoldCase:         true,
+		RejectSpace:      true,
+		RequireLabels:    true,
+		MaxSuffixLength:  10,
+		MaxContentLength: 1024,
+	}
+	registerProfile(&Profile{
+		Name:            ProfileSnsStrict,
+		AllowJSON:       true,
+		AllowText:       true,
+		FoldContentType: true,
+		RequireCharset:  true,
+		JSON:            strict,
+		Text:            strict,
+	})
+	compatible := strict
+	compatible.TrimSpace = true
+	registerProfile(&Profile{
+		Name:            ProfileUnisatCompatible,
+		AllowJSON:       true,
+		AllowText:       true,
+		FoldContentType: true,
+		JSON:            compatible,
+		Text:            compatible,
+	})
+}
+
+// Profile 一套名称索引规则。各市场和索引器对边界情况的处理不一致，所有校验都集中在这里
+type Profile struct {
+	Name string
+	// 接受 {"p":"sns","op":"reg","name":"..."} 格式的内容
+	AllowJSON bool
+	// 接受 "label.suffix" 纯文本内容
+	AllowText bool
+	// content type 忽略大小写和空格
+	FoldContentType bool
+	// text/plain 必须声明 charset=utf-8
+	RequireCharset bool
+	// json 内容中名称的规则
+	JSON NameRules
+	// 纯文本内容的规则
+	Text NameRules
+}
+
+// NameRules 校验一个 "label.suffix" 名称。零值只要求恰好一个点，并拒绝大写
+type NameRules struct {
+	// 去掉首尾空白，而不是拒绝
+	TrimSpace bool
+	// 大写转为小写，而不是拒绝
+	FoldCase bool
+	// 保留铭刻时的大小写
+	KeepCase bool
+	// 拒绝任何 Unicode 空白
+	RejectSpace bool
+	// 拒绝包含其中任一字符的名称
+	RejectChars string
+	// 标签和后缀都不能为空
+	RequireLabels bool
+	// 0 表示不限
+	MaxSuffixLength int
+	// 0 表示不限
+	MaxContentLength uint64
+}
+
+// DefaultProfile 未配置规则集时使用，保持索引器最初的规则，已有的数据库不受影响
+func DefaultProfile() *Profile {
+	profile, _ := GetProfile(ProfileBaseline)
+	return profile
+}
+
+func GetProfile(name string) (*Profile, error) {
+	profileLock.Lock()
+	defer profileLock.Unlock()
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown name profile %q", name)
+	}
+	return profile, nil
+}
+
+func ProfileList() []*Profile {
+	profileLock.Lock()
+	defer profileLock.Unlock()
+	list := make([]*Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, profile)
+	}
+	return list
+}
+
+func registerProfile(profile *Profile) {
+	profileLock.Lock()
+	profiles[profile.Name] = profile
+	profileLock.Unlock()
+}
+
+func (p *Profile) ValidContentType(contentType string) bool {
+	if p.FoldContentType {
+		contentType = strings.ToLower(strings.ReplaceAll(contentType, " ", ""))
+	}
+	if strings.Contains(contentType, "application/json") {
+		return p.AllowJSON
+	}
+
+	if strings.Contains(contentType, "text/plain") {
+		if p.RequireCharset && !strings.Contains(contentType, "charset=utf-8") {
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// NormalizeName 按纯文本规则校验并规范化 "label.suffix" 名称
+func (p *Profile) NormalizeName(raw string) (string, bool) {
+	return p.Text.Normalize(raw)
+}
+
+// ValidLength 内容长度是否在限制内
+func (r NameRules) ValidLength(contentLength uint64) bool {
+	return r.MaxContentLength == 0 || contentLength <= r.MaxContentLength
+}
+
+// Normalize 校验并规范化 "label.suffix" 名称。xn-- 开头的标签先解码，名称按 Unicode 形式保存
+func (r NameRules) Normalize(raw string) (string, bool) {
+	name := raw
+	if r.TrimSpace {
+		name = strings.TrimSpace(name)
+	}
+	name = ToUnicode(name)
+
+	if r.RejectSpace && strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return "", false
+	}
+	if r.RejectChars != "" && strings.ContainsAny(name, r.RejectChars) {
+		return "", false
+	}
+
+	if r.FoldCase {
+		name = strings.ToLower(name)
+	} else if !r.KeepCase && strings.ToLower(name) != name {
+		return "", false
+	}
+
+	if strings.Count(name, ".") != 1 {
+		return "", false
+	}
+
+	parts := strings.Split(name, ".")
+	if r.RequireLabels && (parts[0] == "" || parts[1] == "") {
+		return "", false
+	}
+
+	if r.MaxSuffixLength > 0 && len(parts[1]) > r.MaxSuffixLength {
+		return "", false
+	}
+
+	return name, true
+}
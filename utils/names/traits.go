@@ -0,0 +1,128 @@
+package names
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// 字符类型
+const (
+	CharsetDigit  = "digit"
+	CharsetLetter = "letter"
+	CharsetAlnum  = "alnum"
+	CharsetEmoji  = "emoji"
+	CharsetMixed  = "mixed"
+)
+
+// Traits 名称标签的预计算特征，写入 DoMain 供查询过滤
+type Traits struct {
+	Length     int
+	Charset    string
+	HasDigit   bool
+	HasLetter  bool
+	HasEmoji   bool
+	Palindrome bool
+	MaxRun     int
+	Shape      string
+	Mask       string
+	Skeleton   string
+	// emoji 字素数、纯 emoji 名称的分类、是否带肤色修饰
+	EmojiCount int
+	EmojiClass string
+	SkinTone   bool
+	// 用到的书写系统，逗号分隔，如 Cyrillic,Latin
+	Scripts string
+}
+
+// Graphemes 按字素拆分，一个 emoji 序列算一个字符
+func Graphemes(label string) []string {
+	clusters := make([]string, 0, len(label))
+	g := uniseg.NewGraphemes(label)
+	for g.Next() {
+		clusters = append(clusters, g.Str())
+	}
+	return clusters
+}
+
+// ComputeTraits 计算名称标签(不含后缀)的特征
+func ComputeTraits(label string) Traits {
+	clusters := Graphemes(label)
+	t := Traits{Length: len(clusters), Shape: Shape(clusters), Mask: Mask(clusters), Skeleton: Skeleton(label)}
+
+	others := 0
+	run := 0
+	for i, cluster := range clusters {
+		switch {
+		case isDigit(cluster):
+			t.HasDigit = true
+		case isLetter(cluster):
+			t.HasLetter = true
+		case IsEmoji(cluster):
+			t.HasEmoji = true
+			t.EmojiCount++
+			if HasSkinTone(cluster) {
+				t.SkinTone = true
+			}
+		default:
+			others++
+		}
+
+		if i > 0 && cluster == clusters[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if run > t.MaxRun {
+			t.MaxRun = run
+		}
+	}
+
+	switch {
+	case t.Length == 0:
+		t.Charset = CharsetMixed
+	case others > 0, t.HasEmoji && (t.HasDigit || t.HasLetter):
+		t.Charset = CharsetMixed
+	case t.HasEmoji:
+		t.Charset = CharsetEmoji
+	case t.HasDigit && t.HasLetter:
+		t.Charset = CharsetAlnum
+	case t.HasDigit:
+		t.Charset = CharsetDigit
+	default:
+		t.Charset = CharsetLetter
+	}
+
+	t.EmojiClass = EmojiClass(clusters)
+	t.Scripts = strings.Join(Scripts(label), ",")
+
+	t.Palindrome = t.Length > 1
+	for i := 0; i < t.Length/2; i++ {
+		if clusters[i] != clusters[t.Length-1-i] {
+			t.Palindrome = false
+			break
+		}
+	}
+	return t
+}
+
+func isDigit(cluster string) bool {
+	return len(cluster) == 1 && cluster[0] >= '0' && cluster[0] <= '9'
+}
+
+func isLetter(cluster string) bool {
+	for i, r := range cluster {
+		if i == 0 {
+			if !unicode.IsLetter(r) {
+				return false
+			}
+			continue
+		}
+		// combining marks stay part of the letter
+		if !unicode.Is(unicode.M, r) {
+			return false
+		}
+	}
+	return cluster != ""
+}
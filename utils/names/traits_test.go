@@ -0,0 +1,29 @@
+package names_test
+
+import (
+	"testing"
+	"utils/names"
+)
+
+func TestComputeTraits(t *testing.T) {
+	cases := []struct {
+		label string
+		want  names.Traits
+	}{
+		{"1221", names.Traits{Length: 4, Charset: names.CharsetDigit, HasDigit: true, Palindrome: true, MaxRun: 2, Shape: "ABBA", Mask: "NNNN"}},
+		{"aabb", names.Traits{Length: 4, Charset: names.CharsetLetter, HasLetter: true, MaxRun: 2, Shape: "AABB", Mask: "LLLL", Scripts: "Latin"}},
+		{"a1b2", names.Traits{Length: 4, Charset: names.CharsetAlnum, HasDigit: true, HasLetter: true, MaxRun: 1, Shape: "ABCD", Mask: "LNLN", Scripts: "Latin"}},
+		{"000", names.Traits{Length: 3, Charset: names.CharsetDigit, HasDigit: true, Palindrome: true, MaxRun: 3, Shape: "AAA", Mask: "NNN"}},
+		{"7", names.Traits{Length: 1, Charset: names.CharsetDigit, HasDigit: true, MaxRun: 1, Shape: "A", Mask: "N"}},
+		{"a-b", names.Traits{Length: 3, Charset: names.CharsetMixed, HasLetter: true, MaxRun: 1, Shape: "ABC", Mask: "LOL", Scripts: "Latin"}},
+		{"", names.Traits{Charset: names.CharsetMixed}},
+	}
+	for _, c := range cases {
+		got := names.ComputeTraits(c.label)
+		// skeleton 和 emoji 由各自的测试覆盖
+		got.Skeleton = ""
+		if got != c.want {
+			t.Errorf("%q: traits = %+v, want %+v", c.label, got, c.want)
+		}
+	}
+}
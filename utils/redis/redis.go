@@ -10,6 +10,9 @@ import (
 // redis数据超时时间
 const Timeout = 3 * time.Second
 
+// 键不存在时 RedisGet 返回的错误
+var Nil = redis.Nil
+
 // var Client *redis.ClusterClient
 var Client *redis.Client
 
@@ -49,6 +52,11 @@ func RedisDel(key string) *redis.IntCmd {
 	return Client.Del(key)
 }
 
+// 计数加一，返回新值
+func RedisIncr(key string) (int64, error) {
+	return Client.Incr(key).Result()
+}
+
 // 从列表的右边删除第一个数据，并返回删除的数据
 func RedisRpop(key string) *redis.StringCmd {
 	return Client.RPop(key)